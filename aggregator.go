@@ -0,0 +1,105 @@
+package bls
+
+import (
+	"sync"
+)
+
+// ---------------- Aggregator --------------------
+// Running aggregates of the signatures of a message and of the public keys of their signers,
+// collected as they arrive. Each signer counts once and can be removed, subtracting its
+// contribution, for example after its signature failed verification.
+// Contributions are not verified: the aggregate of keys is only safe to verify against if the
+// pops of all signers were verified.
+
+// Aggregator -- running aggregate of (public key, signature) pairs, safe for concurrent use
+// The zero value is an empty Aggregator
+type Aggregator struct {
+	lock    sync.Mutex
+	sig     Sign
+	pub     PublicKey
+	signers []PublicKey // in order of addition
+	sigs    map[string]Sign
+}
+
+// AggregateSnapshot -- the state of an Aggregator at some point
+type AggregateSnapshot struct {
+	Signature Sign
+	PublicKey PublicKey
+	Signers   []PublicKey
+}
+
+// NewAggregator returns an empty Aggregator
+func NewAggregator() *Aggregator {
+	return &Aggregator{sigs: make(map[string]Sign)}
+}
+
+// Add adds sig by pub and reports whether pub was not a signer yet, ignoring it otherwise
+func (a *Aggregator) Add(pub *PublicKey, sig *Sign) bool {
+	id := string(pub.Serialize())
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if _, ok := a.sigs[id]; ok {
+		return false
+	}
+	if a.sigs == nil {
+		a.sigs = make(map[string]Sign)
+	}
+	a.sigs[id] = *sig
+	a.signers = append(a.signers, *pub)
+	a.sig.Add(sig)
+	a.pub.Add(pub)
+	return true
+}
+
+// Remove subtracts the contribution of pub and reports whether it was a signer
+func (a *Aggregator) Remove(pub *PublicKey) bool {
+	id := string(pub.Serialize())
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	sig, ok := a.sigs[id]
+	if !ok {
+		return false
+	}
+	delete(a.sigs, id)
+	for i := range a.signers {
+		if a.signers[i].IsEqual(pub) {
+			a.signers = append(a.signers[:i], a.signers[i+1:]...)
+			break
+		}
+	}
+	a.sig.Sub(&sig)
+	a.pub.Sub(pub)
+	return true
+}
+
+// Has reports whether pub is a signer
+func (a *Aggregator) Has(pub *PublicKey) bool {
+	id := string(pub.Serialize())
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	_, ok := a.sigs[id]
+	return ok
+}
+
+// Len -- the number of signers
+func (a *Aggregator) Len() int {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return len(a.signers)
+}
+
+// Snapshot returns the current aggregates and signers in order of addition
+func (a *Aggregator) Snapshot() *AggregateSnapshot {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return &AggregateSnapshot{
+		Signature: a.sig,
+		PublicKey: a.pub,
+		Signers:   append([]PublicKey{}, a.signers...),
+	}
+}
+
+// Verify checks that the signers signed message, which requires at least one signer
+func (s *AggregateSnapshot) Verify(message []byte) bool {
+	return len(s.Signers) > 0 && s.Signature.Verify(&s.PublicKey, message)
+}
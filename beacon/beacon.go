@@ -0,0 +1,169 @@
+// Package beacon implements a drand style threshold randomness beacon.
+//
+// Each round the members of a committee sign the round message with their threshold
+// shares (see SecretKey.Set), any Threshold partial signatures are combined with
+// Sign.Recover into the group signature, and the round randomness is the hash of that
+// signature. The group signature is unique, so it is the same whichever members took part,
+// and it is verified against the group PublicKey alone.
+//
+// Round messages are
+//
+//	chained:   SHA256(previous signature | round)
+//	unchained: SHA256(round)
+//
+// with round encoded as 8 bytes big-endian. In chained mode the previous signature of
+// round 1 is the genesis seed of the chain.
+package beacon
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+// Mode -- how round messages are built
+type Mode int
+
+const (
+	// Chained rounds sign the previous signature along with the round number
+	Chained Mode = iota
+	// Unchained rounds sign the round number only, so a round can be produced without the previous one
+	Unchained
+)
+
+// String --
+func (m Mode) String() string {
+	switch m {
+	case Chained:
+		return "chained"
+	case Unchained:
+		return "unchained"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// Chain -- the public parameters of a beacon
+type Chain struct {
+	Mode      Mode
+	GroupKey  bls.PublicKey
+	Threshold int
+	// Genesis -- the previous signature of round 1 in chained mode
+	Genesis []byte
+}
+
+// Beacon -- the output of a round
+type Beacon struct {
+	Round uint64
+	// PreviousSignature -- the signature of the previous round, nil in unchained mode
+	PreviousSignature []byte
+	Signature         []byte
+}
+
+// Partial -- a partial signature of a round by the member with the given id
+type Partial struct {
+	ID        bls.ID
+	Round     uint64
+	Signature bls.Sign
+}
+
+// Randomness returns the randomness of the round, the hash of its signature
+func (b *Beacon) Randomness() [sha256.Size]byte {
+	return sha256.Sum256(b.Signature)
+}
+
+// Message returns the message signed in round with prevSig as the previous signature
+// prevSig is ignored in unchained mode
+func (c *Chain) Message(round uint64, prevSig []byte) []byte {
+	h := sha256.New()
+	if c.Mode == Chained {
+		h.Write(prevSig)
+	}
+	var r [8]byte
+	binary.BigEndian.PutUint64(r[:], round)
+	h.Write(r[:])
+	return h.Sum(nil)
+}
+
+// PartialSign signs round with the threshold share of the member with the given id
+func (c *Chain) PartialSign(share *bls.SecretKey, id *bls.ID, round uint64, prevSig []byte) Partial {
+	return Partial{ID: *id, Round: round, Signature: *share.Sign(c.Message(round, prevSig))}
+}
+
+// VerifyPartial checks a partial signature against the public key share of its member (see PublicKey.Set)
+func (c *Chain) VerifyPartial(pubShare *bls.PublicKey, p *Partial, prevSig []byte) bool {
+	return p.Signature.Verify(pubShare, c.Message(p.Round, prevSig))
+}
+
+// Combine recovers the group signature of round from at least Threshold partials with distinct ids
+// Partials should be checked with VerifyPartial first: an invalid partial makes Combine fail
+func (c *Chain) Combine(round uint64, prevSig []byte, partials []Partial) (*Beacon, error) {
+	if c.Threshold <= 0 {
+		return nil, fmt.Errorf("err beacon:bad threshold %d", c.Threshold)
+	}
+	idVec := make([]bls.ID, 0, c.Threshold)
+	signVec := make([]bls.Sign, 0, c.Threshold)
+	for i := range partials {
+		if len(idVec) == c.Threshold {
+			break
+		}
+		p := &partials[i]
+		if p.Round != round {
+			return nil, fmt.Errorf("err beacon:partial for round %d, expected %d", p.Round, round)
+		}
+		duplicate := false
+		for j := range idVec {
+			if idVec[j].IsEqual(&p.ID) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			idVec = append(idVec, p.ID)
+			signVec = append(signVec, p.Signature)
+		}
+	}
+	if len(idVec) < c.Threshold {
+		return nil, fmt.Errorf("err beacon:%d distinct partials, need %d", len(idVec), c.Threshold)
+	}
+	var sig bls.Sign
+	if err := sig.Recover(signVec, idVec); err != nil {
+		return nil, err
+	}
+	b := &Beacon{Round: round, Signature: sig.Serialize()}
+	if c.Mode == Chained {
+		b.PreviousSignature = append([]byte{}, prevSig...)
+	}
+	if !c.Verify(b) {
+		return nil, fmt.Errorf("err beacon:recovered signature of round %d does not verify", round)
+	}
+	return b, nil
+}
+
+// Verify checks the signature of a round against the group key, and the genesis link of round 1 in chained mode
+func (c *Chain) Verify(b *Beacon) bool {
+	if len(b.Signature) == 0 {
+		return false
+	}
+	if c.Mode == Chained && b.Round == 1 && string(b.PreviousSignature) != string(c.Genesis) {
+		return false
+	}
+	var sig bls.Sign
+	if err := sig.Deserialize(b.Signature); err != nil {
+		return false
+	}
+	return sig.Verify(&c.GroupKey, c.Message(b.Round, b.PreviousSignature))
+}
+
+// VerifyNext checks that next follows prev: the next round, linked by its previous signature in chained mode
+func (c *Chain) VerifyNext(prev *Beacon, next *Beacon) bool {
+	if next.Round != prev.Round+1 {
+		return false
+	}
+	if c.Mode == Chained && string(next.PreviousSignature) != string(prev.Signature) {
+		return false
+	}
+	return c.Verify(next)
+}
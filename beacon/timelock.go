@@ -0,0 +1,79 @@
+package beacon
+
+import (
+	"encoding/pem"
+	"fmt"
+	"strconv"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/ibe"
+)
+
+// Timelock encryption uses an unchained beacon as an identity based encryption key generator:
+// the identity of a round is its message and its private key is the group signature of the
+// round, so a ciphertext for a future round can be decrypted once that round is published.
+// Chained beacons cannot be used since their messages depend on signatures not known in advance.
+//
+// Ciphertexts are armored as a PEM block holding the round and the ibe CCA ciphertext:
+//
+//	-----BEGIN BLS TIMELOCK-----
+//	Round: 1234
+//	Version: 1
+//
+//	<base64 of the ibe.EncryptCCA ciphertext>
+//	-----END BLS TIMELOCK-----
+//
+// The Round header is not authenticated by itself, but the ciphertext only decrypts with
+// the signature of the round it was encrypted to.
+
+// timelockType -- the PEM block type of timelock ciphertexts
+const timelockType = "BLS TIMELOCK"
+
+// timelockVersion -- the version of the armored format
+const timelockVersion = "1"
+
+// TimelockEncrypt encrypts plaintext so that it can only be decrypted with the signature of round
+// by the unchained beacon with group key groupPub
+func TimelockEncrypt(groupPub *bls.PublicKey, round uint64, plaintext []byte) ([]byte, error) {
+	unchained := Chain{Mode: Unchained}
+	ciphertext, err := ibe.EncryptCCA(groupPub, unchained.Message(round, nil), plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{
+		Type:    timelockType,
+		Headers: map[string]string{"Round": strconv.FormatUint(round, 10), "Version": timelockVersion},
+		Bytes:   ciphertext,
+	}), nil
+}
+
+// TimelockDecrypt decrypts an armored ciphertext with the group signature of its round
+// (the Signature of the round's Beacon, see TimelockRound)
+func TimelockDecrypt(roundSig *bls.Sign, ciphertext []byte) ([]byte, error) {
+	block, _, err := decodeTimelock(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return ibe.Decrypt(roundSig, block.Bytes)
+}
+
+// TimelockRound returns the round whose signature decrypts an armored ciphertext
+func TimelockRound(ciphertext []byte) (uint64, error) {
+	_, round, err := decodeTimelock(ciphertext)
+	return round, err
+}
+
+func decodeTimelock(ciphertext []byte) (*pem.Block, uint64, error) {
+	block, _ := pem.Decode(ciphertext)
+	if block == nil || block.Type != timelockType {
+		return nil, 0, fmt.Errorf("err beacon:no %s block", timelockType)
+	}
+	if v := block.Headers["Version"]; v != timelockVersion {
+		return nil, 0, fmt.Errorf("err beacon:unsupported timelock version %q", v)
+	}
+	round, err := strconv.ParseUint(block.Headers["Round"], 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("err beacon:bad timelock round %q", block.Headers["Round"])
+	}
+	return block, round, nil
+}
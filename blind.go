@@ -0,0 +1,42 @@
+package bls
+
+// ---------------- Blind Signatures --------------------
+// The requester blinds H(msg) with a random factor r, the signer signs the blinded point
+// without learning msg, and the requester removes r to get an ordinary signature on msg:
+//
+//	blinded = r * H(msg), blindSig = sec * blinded, sig = blindSig / r = sec * H(msg)
+//
+// A signer using SignBlinded signs arbitrary points, so a signing key should be
+// dedicated to blind issuance and never be used to sign protocol messages directly.
+
+// Blind returns the blinded hash of msg and the blinding factor needed by Unblind
+// The factor must be kept secret by the requester
+func Blind(msg []byte) (blinded *G1, factor Fr) {
+	var h G1
+	if err := h.HashAndMapTo(msg); err != nil {
+		panic(err)
+	}
+	for factor.IsZero() {
+		factor.SetByCSPRNG()
+	}
+	blinded = new(G1)
+	G1MulCT(blinded, &h, &factor)
+	return blinded, factor
+}
+
+// SignBlinded signs a blinded message hash produced by Blind
+func (sec *SecretKey) SignBlinded(blinded *G1) (sign *Sign) {
+	sign = new(Sign)
+	G1MulCT(&sign.v, blinded, &sec.v)
+	return sign
+}
+
+// Unblind removes the blinding factor from a signature returned by SignBlinded
+// The result verifies with Sign.Verify against the signer's public key and the original message
+func Unblind(sig *Sign, factor *Fr) (sign *Sign) {
+	var inv Fr
+	FrInv(&inv, factor)
+	sign = new(Sign)
+	G1MulCT(&sign.v, &sig.v, &inv)
+	return sign
+}
@@ -87,6 +87,17 @@ func (id *ID) IsEqual(rhs *ID) bool {
 	return id.v.IsEqual(&rhs.v)
 }
 
+// checkIDs -- rejects zero ids, whose share is the secret itself
+// mcl checks them only for more than one id
+func checkIDs(idVec []ID) error {
+	for i := range idVec {
+		if idVec[i].v.IsZero() {
+			return fmt.Errorf("zero id")
+		}
+	}
+	return nil
+}
+
 // ---------------- Secret Key Functions --------------------
 
 // SecretKey
@@ -159,11 +170,30 @@ func (sec *SecretKey) SetByCSPRNG() {
 	sec.v.SetByCSPRNG()
 }
 
+// SetHashOf sets secret key to the hash of buf
+// This is not a KeyGen: use KeyGen for deriving keys from input keying material
+func (sec *SecretKey) SetHashOf(buf []byte) error {
+	var p unsafe.Pointer
+	if len(buf) > 0 {
+		// #nosec
+		p = unsafe.Pointer(&buf[0])
+	}
+	if C.blsHashToSecretKey(sec.getPointer(), p, C.size_t(len(buf))) != 0 {
+		return fmt.Errorf("err blsHashToSecretKey")
+	}
+	return nil
+}
+
 // Add aggregates 2 secret keys
 func (sec *SecretKey) Add(rhs *SecretKey) {
 	FrAdd(&sec.v, &sec.v, &rhs.v)
 }
 
+// Sub subtracts rhs from sec
+func (sec *SecretKey) Sub(rhs *SecretKey) {
+	C.blsSecretKeySub(sec.getPointer(), rhs.getPointer())
+}
+
 // GetMasterSecretKey
 func (sec *SecretKey) GetMasterSecretKey(k int) (msk []SecretKey) {
 	msk = make([]SecretKey, k)
@@ -182,6 +212,9 @@ func (sec *SecretKey) Set(msk []SecretKey, id *ID) error {
 
 // Recover --
 func (sec *SecretKey) Recover(secVec []SecretKey, idVec []ID) error {
+	if err := checkIDs(idVec); err != nil {
+		return fmt.Errorf("err SecretKey.Recover:%v", err)
+	}
 	// #nosec
 	return FrLagrangeInterpolation(&sec.v, *(*[]Fr)(unsafe.Pointer(&idVec)), *(*[]Fr)(unsafe.Pointer(&secVec)))
 }
@@ -210,6 +243,13 @@ func GetMasterPublicKey(msk []SecretKey) (mpk []PublicKey) {
 	return mpk
 }
 
+// GetGeneratorOfG2 -- the base point Q of public keys, pub = sec * Q
+func GetGeneratorOfG2() (q G2) {
+	// #nosec
+	C.blsGetGeneratorOfG2((*C.blsPublicKey)(unsafe.Pointer(&q)))
+	return q
+}
+
 // getPointer --
 func (pub *PublicKey) getPointer() (p *C.blsPublicKey) {
 	// #nosec
@@ -256,6 +296,17 @@ func (pub *PublicKey) Add(rhs *PublicKey) {
 	G2Add(&pub.v, &pub.v, &rhs.v)
 }
 
+// Sub --
+func (pub *PublicKey) Sub(rhs *PublicKey) {
+	C.blsPublicKeySub(pub.getPointer(), rhs.getPointer())
+}
+
+// IsValid reports whether pub is a point of order r other than zero
+// Verify does not check it: a zero key and a zero signature verify any message
+func (pub *PublicKey) IsValid() bool {
+	return !pub.v.IsZero() && pub.v.IsValidOrder()
+}
+
 // Set --
 func (pub *PublicKey) Set(mpk []PublicKey, id *ID) error {
 	// #nosec
@@ -264,6 +315,9 @@ func (pub *PublicKey) Set(mpk []PublicKey, id *ID) error {
 
 // Recover --
 func (pub *PublicKey) Recover(pubVec []PublicKey, idVec []ID) error {
+	if err := checkIDs(idVec); err != nil {
+		return fmt.Errorf("err PublicKey.Recover:%v", err)
+	}
 	// #nosec
 	return G2LagrangeInterpolation(&pub.v, *(*[]Fr)(unsafe.Pointer(&idVec)), *(*[]G2)(unsafe.Pointer(&pubVec)))
 }
@@ -347,8 +401,21 @@ func (sign *Sign) Add(rhs *Sign) {
 	C.blsSignatureAdd(sign.getPointer(), rhs.getPointer())
 }
 
+// Sub --
+func (sign *Sign) Sub(rhs *Sign) {
+	C.blsSignatureSub(sign.getPointer(), rhs.getPointer())
+}
+
+// IsValid reports whether sign is a point of order r other than zero
+func (sign *Sign) IsValid() bool {
+	return !sign.v.IsZero() && sign.v.IsValidOrder()
+}
+
 // Recover --
 func (sign *Sign) Recover(signVec []Sign, idVec []ID) error {
+	if err := checkIDs(idVec); err != nil {
+		return fmt.Errorf("err Sign.Recover:%v", err)
+	}
 	// #nosec
 	return G1LagrangeInterpolation(&sign.v, *(*[]Fr)(unsafe.Pointer(&idVec)), *(*[]G1)(unsafe.Pointer(&signVec)))
 }
@@ -392,3 +459,75 @@ func (sign *Sign) VerifyAggregatedHashes(pubKeys []PublicKey, hashes []byte, hSi
 		C.size_t(hSize),
 		C.ulong(n)) == 1
 }
+
+// AggregateVerify verifies an aggregated signature created by n entities, entity i signing messages[i] with Sign
+//
+//	e(aggSig, Q) = prod_i e(H(messages[i]), pubKeys[i])
+//
+// It is false if sign or any of pubKeys is not valid, see IsValid
+// @note does not check duplication of messages, which callers must reject unless all pubKeys have a verified pop
+func (sign *Sign) AggregateVerify(pubKeys []PublicKey, messages [][]byte) bool {
+	if len(pubKeys) == 0 || len(pubKeys) != len(messages) || !sign.IsValid() {
+		return false
+	}
+	for i := range pubKeys {
+		if !pubKeys[i].IsValid() {
+			return false
+		}
+	}
+	var e, t GT
+	var neg G1
+	q := GetGeneratorOfG2()
+	G1Neg(&neg, &sign.v)
+	MillerLoop(&e, &neg, &q)
+	for i := range pubKeys {
+		var h G1
+		if len(messages[i]) == 0 || h.HashAndMapTo(messages[i]) != nil {
+			return false
+		}
+		MillerLoop(&t, &h, &pubKeys[i].v)
+		GTMul(&e, &e, &t)
+	}
+	FinalExp(&e, &e)
+	return e.IsOne()
+}
+
+// ---------------- Casts --------------------
+// The following functions give access to the group elements underlying keys and signatures
+// without copying, for protocols built on the mcl api
+
+// CastFromSecretKey --
+func CastFromSecretKey(in *SecretKey) *Fr {
+	// #nosec
+	return (*Fr)(unsafe.Pointer(in))
+}
+
+// CastToSecretKey --
+func CastToSecretKey(in *Fr) *SecretKey {
+	// #nosec
+	return (*SecretKey)(unsafe.Pointer(in))
+}
+
+// CastFromPublicKey --
+func CastFromPublicKey(in *PublicKey) *G2 {
+	// #nosec
+	return (*G2)(unsafe.Pointer(in))
+}
+
+// CastToPublicKey --
+func CastToPublicKey(in *G2) *PublicKey {
+	// #nosec
+	return (*PublicKey)(unsafe.Pointer(in))
+}
+
+// CastFromSign --
+func CastFromSign(in *Sign) *G1 {
+	// #nosec
+	return (*G1)(unsafe.Pointer(in))
+}
+
+// CastToSign --
+func CastToSign(in *G1) *Sign {
+	// #nosec
+	return (*Sign)(unsafe.Pointer(in))
+}
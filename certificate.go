@@ -0,0 +1,152 @@
+package bls
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+// ---------------- Aggregate Certificates --------------------
+// An aggregate signature of a message by members of a committee along with the set of signers,
+// as a bitfield over the positions of the members in the ordered committee.
+//
+// Encoding: uvarint(committee size) | bitfield | signature
+// bit i of the bitfield is bit i%8 of byte i/8 and the unused high bits of the last byte are zero.
+
+// AggregateCertificate -- an aggregate signature and its signers
+type AggregateCertificate struct {
+	Signature Sign
+	size      int
+	signers   []byte
+}
+
+// NewAggregateCertificate returns a certificate without signers for a committee of size members
+func NewAggregateCertificate(size int) *AggregateCertificate {
+	if size < 0 {
+		size = 0
+	}
+	return &AggregateCertificate{size: size, signers: make([]byte, (size+7)/8)}
+}
+
+// Size -- the size of the committee
+func (c *AggregateCertificate) Size() int {
+	return c.size
+}
+
+// Count -- the number of signers
+func (c *AggregateCertificate) Count() int {
+	n := 0
+	for _, b := range c.signers {
+		n += bits.OnesCount8(b)
+	}
+	return n
+}
+
+// Has reports whether the member at index signed
+func (c *AggregateCertificate) Has(index int) bool {
+	return index >= 0 && index < c.size && c.signers[index/8]&(1<<uint(index%8)) != 0
+}
+
+// Signers returns the indices of the signers in increasing order
+func (c *AggregateCertificate) Signers() []int {
+	indices := make([]int, 0, c.Count())
+	for i := 0; i < c.size; i++ {
+		if c.Has(i) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// Add adds the signature of the member at index
+func (c *AggregateCertificate) Add(index int, sig *Sign) error {
+	if index < 0 || index >= c.size {
+		return fmt.Errorf("err AggregateCertificate:index %d out of committee of %d", index, c.size)
+	}
+	if c.Has(index) {
+		return fmt.Errorf("err AggregateCertificate:index %d already signed", index)
+	}
+	c.signers[index/8] |= 1 << uint(index%8)
+	c.Signature.Add(sig)
+	return nil
+}
+
+// Merge adds the signers and signature of rhs, which must have no signer in common with c
+func (c *AggregateCertificate) Merge(rhs *AggregateCertificate) error {
+	if c.size != rhs.size {
+		return fmt.Errorf("err AggregateCertificate:committee sizes %d and %d", c.size, rhs.size)
+	}
+	for i := range c.signers {
+		if c.signers[i]&rhs.signers[i] != 0 {
+			return fmt.Errorf("err AggregateCertificate:overlapping signers")
+		}
+	}
+	for i := range c.signers {
+		c.signers[i] |= rhs.signers[i]
+	}
+	c.Signature.Add(&rhs.Signature)
+	return nil
+}
+
+// Verify checks that the signers, the members of the ordered committee pubVec at the set bits, signed msg
+// The signature is verified under the sum of their public keys, which is only secure if their pops were verified
+func (c *AggregateCertificate) Verify(pubVec []PublicKey, msg []byte) bool {
+	if len(pubVec) != c.size || len(msg) == 0 {
+		return false
+	}
+	var agg PublicKey
+	n := 0
+	for i := range pubVec {
+		if c.Has(i) {
+			agg.Add(&pubVec[i])
+			n++
+		}
+	}
+	if n == 0 {
+		return false
+	}
+	return c.Signature.Verify(&agg, msg)
+}
+
+// Serialize returns the canonical encoding of c
+func (c *AggregateCertificate) Serialize() []byte {
+	buf := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(c.signers)+48)
+	buf = buf[:binary.PutUvarint(buf, uint64(c.size))]
+	buf = append(buf, c.signers...)
+	return append(buf, c.Signature.Serialize()...)
+}
+
+// Deserialize sets c from its canonical encoding, rejecting any other encoding
+func (c *AggregateCertificate) Deserialize(buf []byte) error {
+	size, n := binary.Uvarint(buf)
+	if n <= 0 || n != uvarintLen(size) {
+		return fmt.Errorf("err AggregateCertificate:bad committee size")
+	}
+	buf = buf[n:]
+	nBytes := (size + 7) / 8
+	if size > uint64(len(buf))*8 || nBytes > uint64(len(buf)) {
+		return fmt.Errorf("err AggregateCertificate:short buffer")
+	}
+	signers := append([]byte{}, buf[:nBytes]...)
+	if size%8 != 0 && signers[nBytes-1]>>(size%8) != 0 {
+		return fmt.Errorf("err AggregateCertificate:bits set beyond committee size")
+	}
+	var sig Sign
+	if err := sig.Deserialize(buf[nBytes:]); err != nil {
+		return err
+	}
+	c.Signature = sig
+	c.size = int(size)
+	c.signers = signers
+	return nil
+}
+
+// uvarintLen -- length of the minimal uvarint encoding of x
+func uvarintLen(x uint64) int {
+	n := 1
+	for x >= 0x80 {
+		x >>= 7
+		n++
+	}
+	return n
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+// encodings of keys and signatures
+const (
+	formatSerialized = "serialized" // SerializeToHexStr, the default
+	formatHex        = "hex"        // GetHexString, big-endian for secret keys
+	formatLE         = "le"         // hex of GetLittleEndian, secret keys only
+	formatDec        = "dec"        // GetDecString, secret keys only
+)
+
+// value types of convert
+const (
+	typeSecretKey = "sk"
+	typePublicKey = "pk"
+	typeSign      = "sig"
+)
+
+func decodeSecretKey(sec *bls.SecretKey, s string, format string) error {
+	switch format {
+	case formatSerialized:
+		return sec.DeserializeHexStr(strings.TrimPrefix(s, "0x"))
+	case formatHex:
+		return sec.SetHexString(strings.TrimPrefix(s, "0x"))
+	case formatLE:
+		buf, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+		if err != nil {
+			return err
+		}
+		return sec.SetLittleEndian(buf)
+	case formatDec:
+		return sec.SetDecString(s)
+	}
+	return fmt.Errorf("unsupported secret key format %q", format)
+}
+
+func encodeSecretKey(sec *bls.SecretKey, format string) (string, error) {
+	switch format {
+	case formatSerialized:
+		return sec.SerializeToHexStr(), nil
+	case formatHex:
+		return sec.GetHexString(), nil
+	case formatLE:
+		return hex.EncodeToString(sec.GetLittleEndian()), nil
+	case formatDec:
+		return sec.GetDecString(), nil
+	}
+	return "", fmt.Errorf("unsupported secret key format %q", format)
+}
+
+func decodePublicKey(pub *bls.PublicKey, s string, format string) error {
+	switch format {
+	case formatSerialized:
+		return pub.DeserializeHexStr(strings.TrimPrefix(s, "0x"))
+	case formatHex:
+		return pub.SetHexString(s)
+	}
+	return fmt.Errorf("unsupported public key format %q", format)
+}
+
+func encodePublicKey(pub *bls.PublicKey, format string) (string, error) {
+	switch format {
+	case formatSerialized:
+		return pub.SerializeToHexStr(), nil
+	case formatHex:
+		return pub.GetHexString(), nil
+	}
+	return "", fmt.Errorf("unsupported public key format %q", format)
+}
+
+func decodeSign(sig *bls.Sign, s string, format string) error {
+	switch format {
+	case formatSerialized:
+		return sig.DeserializeHexStr(strings.TrimPrefix(s, "0x"))
+	case formatHex:
+		return sig.SetHexString(s)
+	}
+	return fmt.Errorf("unsupported signature format %q", format)
+}
+
+func encodeSign(sig *bls.Sign, format string) (string, error) {
+	switch format {
+	case formatSerialized:
+		return sig.SerializeToHexStr(), nil
+	case formatHex:
+		return sig.GetHexString(), nil
+	}
+	return "", fmt.Errorf("unsupported signature format %q", format)
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+// keyOutput -- the output of keygen
+type keyOutput struct {
+	SecretKey string `json:"secretKey"`
+	PublicKey string `json:"publicKey"`
+	Path      string `json:"path,omitempty"`
+}
+
+func runKeygen(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("keygen")
+	ikmHex := fs.String("ikm", "", "hex input keying material of at least 32 bytes for KeyGen")
+	keyInfo := fs.String("info", "", "key info for KeyGen")
+	seedHex := fs.String("seed", "", "hex EIP-2333 seed of at least 32 bytes")
+	path := fs.String("path", "m", "EIP-2334 path of the key derived from -seed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *ikmHex != "" && *seedHex != "" {
+		return errors.New("-ikm and -seed are exclusive")
+	}
+	var sec bls.SecretKey
+	out := keyOutput{}
+	switch {
+	case *ikmHex != "":
+		ikm, err := hex.DecodeString(strings.TrimPrefix(*ikmHex, "0x"))
+		if err != nil {
+			return fmt.Errorf("-ikm: %v", err)
+		}
+		if sec, err = bls.KeyGen(ikm, []byte(*keyInfo)); err != nil {
+			return err
+		}
+	case *seedHex != "":
+		seed, err := hex.DecodeString(strings.TrimPrefix(*seedHex, "0x"))
+		if err != nil {
+			return fmt.Errorf("-seed: %v", err)
+		}
+		if err := bls.ValidatePath(*path); err != nil {
+			return err
+		}
+		if sec, err = bls.DeriveSKFromPath(seed, *path); err != nil {
+			return err
+		}
+		out.Path = *path
+	default:
+		sec.SetByCSPRNG()
+	}
+	out.SecretKey = sec.SerializeToHexStr()
+	out.PublicKey = sec.GetPublicKey().SerializeToHexStr()
+	return writeJSON(stdout, out)
+}
+
+func runPubkey(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("pubkey")
+	skPath := fs.String("sk", "-", "secret key file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	r := &inputReader{stdin: stdin}
+	sec, err := r.secretKey(*skPath)
+	if err != nil {
+		return err
+	}
+	return writeJSON(stdout, map[string]string{fieldPublicKey: sec.GetPublicKey().SerializeToHexStr()})
+}
+
+func runSign(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("sign")
+	skPath := fs.String("sk", "", "secret key file")
+	msgFlags := addMessageFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	r := &inputReader{stdin: stdin}
+	sec, err := r.secretKey(*skPath)
+	if err != nil {
+		return err
+	}
+	msg, err := msgFlags.message(r)
+	if err != nil {
+		return err
+	}
+	out := map[string]string{fieldSignature: sec.Sign(msg).SerializeToHexStr()}
+	// signatures with a key share carry its id for combine
+	if id, err := r.shareID(*skPath); err != nil {
+		return err
+	} else if id != nil {
+		out[fieldID] = id.GetDecString()
+	}
+	return writeJSON(stdout, out)
+}
+
+func runVerify(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("verify")
+	pkPath := fs.String("pk", "", "public key file")
+	sigPath := fs.String("sig", "", "signature file")
+	msgFlags := addMessageFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	r := &inputReader{stdin: stdin}
+	pub, err := r.publicKey(*pkPath)
+	if err != nil {
+		return err
+	}
+	sig, err := r.sign(*sigPath, fieldSignature)
+	if err != nil {
+		return err
+	}
+	msg, err := msgFlags.message(r)
+	if err != nil {
+		return err
+	}
+	return verifyResult(stdout, sig.IsValid() && sig.Verify(pub, msg))
+}
+
+func runAggregate(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("aggregate")
+	pubs := fs.Bool("pub", false, "aggregate public keys instead of signatures")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("no input files")
+	}
+	r := &inputReader{stdin: stdin}
+	if *pubs {
+		var agg bls.PublicKey
+		for i, path := range fs.Args() {
+			pub, err := r.publicKey(path)
+			if err != nil {
+				return err
+			}
+			if i == 0 {
+				agg = *pub
+			} else {
+				agg.Add(pub)
+			}
+		}
+		return writeJSON(stdout, map[string]string{fieldPublicKey: agg.SerializeToHexStr()})
+	}
+	var agg bls.Sign
+	for i, path := range fs.Args() {
+		sig, err := r.sign(path, fieldSignature)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			agg = *sig
+		} else {
+			agg.Add(sig)
+		}
+	}
+	return writeJSON(stdout, map[string]string{fieldSignature: agg.SerializeToHexStr()})
+}
+
+// runAggregateVerify verifies an aggregated signature
+// with a single message all public keys signed it, which is only secure if their pops were verified:
+// the pops given with -pop are verified first and a warning is printed without them.
+// Else the i-th message was signed by the i-th public key and messages must be distinct
+func runAggregateVerify(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("aggregate-verify")
+	sigPath := fs.String("sig", "", "aggregated signature file")
+	var pkPaths, popPaths, msgs, msgsHex stringList
+	fs.Var(&pkPaths, "pk", "public key file (repeated)")
+	fs.Var(&popPaths, "pop", "proof of possession file of the -pk at the same position, with a single message (repeated)")
+	fs.Var(&msgs, "msg", "message as a string (repeated, once per -pk or once for all)")
+	fs.Var(&msgsHex, "msg-hex", "message as hex (repeated, once per -pk or once for all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(pkPaths) == 0 {
+		return errors.New("at least one -pk is required")
+	}
+	var messages [][]byte
+	switch {
+	case len(msgs) > 0 && len(msgsHex) == 0:
+		for _, m := range msgs {
+			messages = append(messages, []byte(m))
+		}
+	case len(msgs) == 0 && len(msgsHex) > 0:
+		for _, m := range msgsHex {
+			msg, err := hex.DecodeString(strings.TrimPrefix(m, "0x"))
+			if err != nil {
+				return fmt.Errorf("-msg-hex: %v", err)
+			}
+			messages = append(messages, msg)
+		}
+	default:
+		return errors.New("messages must be given with either -msg or -msg-hex")
+	}
+	if len(messages) != 1 && len(messages) != len(pkPaths) {
+		return fmt.Errorf("%d messages for %d public keys", len(messages), len(pkPaths))
+	}
+	for _, msg := range messages {
+		if len(msg) == 0 {
+			return errors.New("empty message")
+		}
+	}
+	r := &inputReader{stdin: stdin}
+	sig, err := r.sign(*sigPath, fieldSignature)
+	if err != nil {
+		return err
+	}
+	pubVec := make([]bls.PublicKey, len(pkPaths))
+	for i, path := range pkPaths {
+		pub, err := r.publicKey(path)
+		if err != nil {
+			return err
+		}
+		pubVec[i] = *pub
+	}
+	if len(popPaths) > 0 && (len(messages) != 1 || len(popPaths) != len(pkPaths)) {
+		return fmt.Errorf("%d -pop for %d -pk: give one per -pk with a single message", len(popPaths), len(pkPaths))
+	}
+	if len(messages) == 1 {
+		if len(popPaths) == 0 {
+			fmt.Fprintln(os.Stderr, "bls aggregate-verify: warning: public keys aggregated without -pop, a rogue key can forge the signature")
+		}
+		for i, path := range popPaths {
+			pop, err := r.sign(path, fieldPop)
+			if err != nil {
+				return err
+			}
+			if !pop.VerifyPop(&pubVec[i]) {
+				fmt.Fprintf(os.Stderr, "bls aggregate-verify: %s: invalid proof of possession of %s\n", path, pkPaths[i])
+				return verifyResult(stdout, false)
+			}
+		}
+		agg := pubVec[0]
+		for i := 1; i < len(pubVec); i++ {
+			agg.Add(&pubVec[i])
+		}
+		return verifyResult(stdout, sig.IsValid() && agg.IsValid() && sig.Verify(&agg, messages[0]))
+	}
+	seen := make(map[string]bool, len(messages))
+	for _, msg := range messages {
+		if seen[string(msg)] {
+			return errors.New("duplicate message: use a single -msg for signatures of the same message")
+		}
+		seen[string(msg)] = true
+	}
+	return verifyResult(stdout, sig.AggregateVerify(pubVec, messages))
+}
+
+func runPop(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("pop")
+	skPath := fs.String("sk", "", "secret key file to create a proof of possession for")
+	pkPath := fs.String("pk", "", "public key file to verify -pop for")
+	popPath := fs.String("pop", "", "proof of possession file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	r := &inputReader{stdin: stdin}
+	switch {
+	case *skPath != "" && *pkPath == "" && *popPath == "":
+		sec, err := r.secretKey(*skPath)
+		if err != nil {
+			return err
+		}
+		return writeJSON(stdout, map[string]string{
+			fieldPublicKey: sec.GetPublicKey().SerializeToHexStr(),
+			fieldPop:       sec.GetPop().SerializeToHexStr(),
+		})
+	case *skPath == "" && *pkPath != "" && *popPath != "":
+		pub, err := r.publicKey(*pkPath)
+		if err != nil {
+			return err
+		}
+		pop, err := r.sign(*popPath, fieldPop)
+		if err != nil {
+			return err
+		}
+		return verifyResult(stdout, pop.IsValid() && pop.VerifyPop(pub))
+	}
+	return errors.New("either -sk, or -pk and -pop are required")
+}
+
+func runConvert(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("convert")
+	typ := fs.String("type", typeSecretKey, "value type: sk, pk or sig")
+	in := fs.String("in", "-", "input file")
+	from := fs.String("from", "", "input encoding: serialized, hex, le or dec (le and dec for sk only), by default the format field of the input or serialized")
+	to := fs.String("to", formatHex, "output encoding: serialized, hex, le or dec (le and dec for sk only)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	r := &inputReader{stdin: stdin}
+	if *from == "" {
+		format, err := r.format(*in)
+		if err != nil {
+			return err
+		}
+		*from = format
+	}
+	var field, out string
+	var err error
+	switch *typ {
+	case typeSecretKey:
+		field = fieldSecretKey
+		var s string
+		if s, err = r.value(*in, field); err != nil {
+			return err
+		}
+		var sec bls.SecretKey
+		if err = decodeSecretKey(&sec, s, *from); err != nil {
+			return err
+		}
+		out, err = encodeSecretKey(&sec, *to)
+	case typePublicKey:
+		field = fieldPublicKey
+		var s string
+		if s, err = r.value(*in, field); err != nil {
+			return err
+		}
+		var pub bls.PublicKey
+		if err = decodePublicKey(&pub, s, *from); err != nil {
+			return err
+		}
+		out, err = encodePublicKey(&pub, *to)
+	case typeSign:
+		field = fieldSignature
+		var s string
+		if s, err = r.value(*in, field); err != nil {
+			return err
+		}
+		var sig bls.Sign
+		if err = decodeSign(&sig, s, *from); err != nil {
+			return err
+		}
+		out, err = encodeSign(&sig, *to)
+	default:
+		return fmt.Errorf("unsupported type %q", *typ)
+	}
+	if err != nil {
+		return err
+	}
+	return writeJSON(stdout, map[string]string{field: out, fieldFormat: *to})
+}
@@ -0,0 +1,328 @@
+// Command bls manages BLS keys and signatures from the command line.
+//
+// Keys and signatures are read from files ("-" is stdin) holding either the encoded value or
+// the JSON output of another subcommand, and results are written to stdout as JSON, so that
+// subcommands can be piped:
+//
+//	bls keygen > key.json
+//	bls sign -sk key.json -msg hello | bls verify -pk key.json -sig - -msg hello
+//
+// Values are encoded with SerializeToHexStr unless stated otherwise; use convert to move
+// between the encodings supported by SecretKey, PublicKey and Sign. The JSON output of convert
+// records the encoding in its "format" field and is read back in that encoding.
+// Verification subcommands exit with status 1 when verification fails and errors exit with status 2.
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+// command -- a subcommand reading args and stdin and writing its result to stdout
+type command struct {
+	usage string
+	run   func(args []string, stdin io.Reader, stdout io.Writer) error
+}
+
+var commands = map[string]command{
+	"keygen":           {"generate a secret key at random, from -ikm or from -seed and -path (EIP-2333)", runKeygen},
+	"pubkey":           {"print the public key of -sk", runPubkey},
+	"sign":             {"sign a message with -sk", runSign},
+	"verify":           {"verify -sig of a message under -pk", runVerify},
+	"split":            {"split -sk into shares for a -k of n threshold committee", runSplit},
+	"pubshares":        {"derive the public key shares of ids from the master public key of a committee", runPubshares},
+	"combine":          {"combine -k signature shares into the group signature", runCombine},
+	"recover-pubkey":   {"recover the group public key from -k public key shares", runRecoverPubkey},
+	"aggregate":        {"aggregate the signatures (or public keys with -pub) in the given files", runAggregate},
+	"aggregate-verify": {"verify an aggregated -sig under repeated -pk and message flags", runAggregateVerify},
+	"pop":              {"create a proof of possession of -sk, or verify -pop under -pk", runPop},
+	"convert":          {"convert -in of -type between -from and -to encodings", runConvert},
+}
+
+// errInvalid is returned by subcommands whose verification failed, after printing their result
+var errInvalid = errors.New("verification failed")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout))
+}
+
+// run executes the subcommand named by args[0] and returns the exit status
+func run(args []string, stdin io.Reader, stdout io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "-help" {
+		usage(os.Stderr)
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "bls: unknown command %q\n", args[0])
+		usage(os.Stderr)
+		return 2
+	}
+	if err := cmd.run(args[1:], stdin, stdout); err != nil {
+		if errors.Is(err, errInvalid) {
+			return 1
+		}
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "bls %s: %v\n", args[0], err)
+		}
+		return 2
+	}
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: bls <command> [flags]")
+	fmt.Fprintln(w)
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-18s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "run bls <command> -h for the flags of a command")
+}
+
+// newFlagSet returns a flag set writing errors and usage to stderr
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("bls "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// stringList -- a repeatable string flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// JSON field names of the values read and written by subcommands
+const (
+	fieldSecretKey = "secretKey"
+	fieldPublicKey = "publicKey"
+	fieldSignature = "signature"
+	fieldPop       = "pop"
+	fieldID        = "id"
+	fieldFormat    = "format"
+)
+
+// inputReader reads inputs from files, reading stdin at most once
+type inputReader struct {
+	stdin io.Reader
+	cache map[string][]byte
+}
+
+// read returns the content of the file at path, or of stdin if path is "-"
+func (r *inputReader) read(path string) ([]byte, error) {
+	if path == "" {
+		return nil, errors.New("missing input file")
+	}
+	if buf, ok := r.cache[path]; ok {
+		return buf, nil
+	}
+	var buf []byte
+	var err error
+	if path == "-" {
+		buf, err = io.ReadAll(r.stdin)
+	} else {
+		buf, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.cache == nil {
+		r.cache = make(map[string][]byte)
+	}
+	r.cache[path] = buf
+	return buf, nil
+}
+
+// value returns the encoded value in the file at path
+// if the file holds a JSON object its field is returned, else the trimmed content
+func (r *inputReader) value(path string, field string) (string, error) {
+	buf, err := r.read(path)
+	if err != nil {
+		return "", err
+	}
+	buf = bytes.TrimSpace(buf)
+	if len(buf) == 0 || buf[0] != '{' {
+		return string(buf), nil
+	}
+	var s string
+	found, err := r.field(path, field, &s)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf("%s: no %q field", path, field)
+	}
+	return s, nil
+}
+
+// field decodes the field of the JSON object in the file at path into v and reports whether it was present
+func (r *inputReader) field(path string, field string, v interface{}) (bool, error) {
+	buf, err := r.read(path)
+	if err != nil {
+		return false, err
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		return false, fmt.Errorf("%s: %v", path, err)
+	}
+	raw, ok := obj[field]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return false, fmt.Errorf("%s: field %q: %v", path, field, err)
+	}
+	return true, nil
+}
+
+// format returns the encoding of the values in the file at path
+// it is the "format" field written by convert if the file holds a JSON object with one, else serialized
+func (r *inputReader) format(path string) (string, error) {
+	buf, err := r.read(path)
+	if err != nil {
+		return "", err
+	}
+	buf = bytes.TrimSpace(buf)
+	if len(buf) == 0 || buf[0] != '{' {
+		return formatSerialized, nil
+	}
+	var format string
+	found, err := r.field(path, fieldFormat, &format)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return formatSerialized, nil
+	}
+	return format, nil
+}
+
+// encoded returns the encoded value of field in the file at path and its encoding
+func (r *inputReader) encoded(path string, field string) (string, string, error) {
+	s, err := r.value(path, field)
+	if err != nil {
+		return "", "", err
+	}
+	format, err := r.format(path)
+	if err != nil {
+		return "", "", err
+	}
+	return s, format, nil
+}
+
+func (r *inputReader) secretKey(path string) (*bls.SecretKey, error) {
+	s, format, err := r.encoded(path, fieldSecretKey)
+	if err != nil {
+		return nil, err
+	}
+	sec := new(bls.SecretKey)
+	if err := decodeSecretKey(sec, s, format); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return sec, nil
+}
+
+func (r *inputReader) publicKey(path string) (*bls.PublicKey, error) {
+	s, format, err := r.encoded(path, fieldPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	pub := new(bls.PublicKey)
+	if err := decodePublicKey(pub, s, format); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if !pub.IsValid() {
+		return nil, fmt.Errorf("%s: invalid public key", path)
+	}
+	return pub, nil
+}
+
+func (r *inputReader) sign(path string, field string) (*bls.Sign, error) {
+	s, format, err := r.encoded(path, field)
+	if err != nil {
+		return nil, err
+	}
+	sig := new(bls.Sign)
+	if err := decodeSign(sig, s, format); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return sig, nil
+}
+
+// messageFlags -- the flags selecting the message to sign or verify
+type messageFlags struct {
+	msg     *string
+	msgHex  *string
+	msgFile *string
+}
+
+func addMessageFlags(fs *flag.FlagSet) messageFlags {
+	return messageFlags{
+		msg:     fs.String("msg", "", "message as a string"),
+		msgHex:  fs.String("msg-hex", "", "message as hex"),
+		msgFile: fs.String("msg-file", "", "file holding the raw message (- for stdin)"),
+	}
+}
+
+// message returns the message given by exactly one of the message flags
+func (m messageFlags) message(r *inputReader) ([]byte, error) {
+	var msg []byte
+	var err error
+	switch {
+	case *m.msg != "" && *m.msgHex == "" && *m.msgFile == "":
+		msg = []byte(*m.msg)
+	case *m.msg == "" && *m.msgHex != "" && *m.msgFile == "":
+		msg, err = hex.DecodeString(strings.TrimPrefix(*m.msgHex, "0x"))
+	case *m.msg == "" && *m.msgHex == "" && *m.msgFile != "":
+		msg, err = r.read(*m.msgFile)
+	default:
+		return nil, errors.New("exactly one of -msg, -msg-hex and -msg-file is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(msg) == 0 {
+		return nil, errors.New("empty message")
+	}
+	return msg, nil
+}
+
+// writeJSON writes v to w as indented JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// verifyResult writes the result of a verification and returns errInvalid if it failed
+func verifyResult(w io.Writer, valid bool) error {
+	if err := writeJSON(w, map[string]bool{"valid": valid}); err != nil {
+		return err
+	}
+	if !valid {
+		return errInvalid
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+// runCmd runs the command line args with stdin and returns stdout and the exit status
+func runCmd(t *testing.T, stdin string, args ...string) (string, int) {
+	t.Helper()
+	var stdout bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout)
+	return stdout.String(), code
+}
+
+// mustRun runs the command line args and fails the test unless it exits with status 0
+func mustRun(t *testing.T, stdin string, args ...string) string {
+	t.Helper()
+	out, code := runCmd(t, stdin, args...)
+	if code != 0 {
+		t.Fatalf("bls %s: exit status %d", strings.Join(args, " "), code)
+	}
+	return out
+}
+
+// writeFile writes content to name in dir and returns its path
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// jsonField returns the string field of the JSON object out
+func jsonField(t *testing.T, out string, field string) string {
+	t.Helper()
+	var obj map[string]string
+	if err := json.Unmarshal([]byte(out), &obj); err != nil {
+		t.Fatalf("%q: %v", out, err)
+	}
+	return obj[field]
+}
+
+func TestKeygenSignVerify(t *testing.T) {
+	dir := t.TempDir()
+	key := writeFile(t, dir, "key.json", mustRun(t, "", "keygen"))
+	pub := writeFile(t, dir, "pub.json", mustRun(t, "", "pubkey", "-sk", key))
+	if jsonField(t, mustRun(t, "", "pubkey", "-sk", key), fieldPublicKey) != jsonField(t, mustRun(t, "", "pubkey", "-sk", key), fieldPublicKey) {
+		t.Fatal("pubkey is not deterministic")
+	}
+	sig := mustRun(t, "", "sign", "-sk", key, "-msg", "hello")
+	tests := []struct {
+		name string
+		pk   string
+		msg  []string
+		code int
+	}{
+		{"keygen output", key, []string{"-msg", "hello"}, 0},
+		{"pubkey output", pub, []string{"-msg", "hello"}, 0},
+		{"hex message", pub, []string{"-msg-hex", "68656c6c6f"}, 0},
+		{"other message", pub, []string{"-msg", "world"}, 1},
+		{"two messages", pub, []string{"-msg", "hello", "-msg-hex", "00"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"verify", "-pk", tt.pk, "-sig", "-"}, tt.msg...)
+			if out, code := runCmd(t, sig, args...); code != tt.code {
+				t.Errorf("exit status %d, expected %d: %s", code, tt.code, out)
+			}
+		})
+	}
+
+	// a zero public key is rejected, a zero signature does not verify
+	var zeroPub bls.PublicKey
+	var zeroSig bls.Sign
+	zeroPk := writeFile(t, dir, "zero-pub.json", `{"`+fieldPublicKey+`":"`+zeroPub.SerializeToHexStr()+`"}`)
+	zero := `{"` + fieldSignature + `":"` + zeroSig.SerializeToHexStr() + `"}`
+	if _, code := runCmd(t, zero, "verify", "-pk", zeroPk, "-sig", "-", "-msg", "hello"); code != 2 {
+		t.Error("expected exit status 2 for a zero public key")
+	}
+	if _, code := runCmd(t, zero, "verify", "-pk", pub, "-sig", "-", "-msg", "hello"); code != 1 {
+		t.Error("expected exit status 1 for a zero signature")
+	}
+
+	// keys derived from -ikm are deterministic
+	ikm := strings.Repeat("ab", 32)
+	if mustRun(t, "", "keygen", "-ikm", ikm) != mustRun(t, "", "keygen", "-ikm", ikm) {
+		t.Error("keygen -ikm is not deterministic")
+	}
+	if _, code := runCmd(t, "", "keygen", "-ikm", ikm, "-seed", ikm); code != 2 {
+		t.Error("expected exit status 2 for -ikm with -seed")
+	}
+	if jsonField(t, mustRun(t, "", "keygen", "-seed", ikm, "-path", "m/12381/3600/0/0"), "path") != "m/12381/3600/0/0" {
+		t.Error("keygen -path is not recorded")
+	}
+	if _, code := runCmd(t, "", "keygen", "-seed", ikm, "-path", "m/44/60/0/0"); code != 2 {
+		t.Error("expected exit status 2 for a path without the EIP-2334 purpose")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	dir := t.TempDir()
+	key := writeFile(t, dir, "key.json", mustRun(t, "", "keygen"))
+	pub := writeFile(t, dir, "pub.json", mustRun(t, "", "pubkey", "-sk", key))
+	sig := writeFile(t, dir, "sig.json", mustRun(t, "", "sign", "-sk", key, "-msg", "hello"))
+	tests := []struct {
+		typ     string
+		formats []string
+	}{
+		{typeSecretKey, []string{formatSerialized, formatHex, formatLE, formatDec}},
+		{typePublicKey, []string{formatSerialized, formatHex}},
+		{typeSign, []string{formatSerialized, formatHex}},
+	}
+	for _, tt := range tests {
+		for _, format := range tt.formats {
+			t.Run(tt.typ+"-"+format, func(t *testing.T) {
+				in := map[string]string{typeSecretKey: key, typePublicKey: pub, typeSign: sig}[tt.typ]
+				converted := writeFile(t, dir, tt.typ+"-"+format+".json", mustRun(t, "", "convert", "-type", tt.typ, "-in", in, "-to", format))
+				// the converted value is used in its format
+				sk, pk, sg := key, pub, sig
+				switch tt.typ {
+				case typeSecretKey:
+					sk = converted
+					sg = writeFile(t, dir, "sig-"+format+".json", mustRun(t, "", "sign", "-sk", sk, "-msg", "hello"))
+				case typePublicKey:
+					pk = converted
+				case typeSign:
+					sg = converted
+				}
+				if out, code := runCmd(t, "", "verify", "-pk", pk, "-sig", sg, "-msg", "hello"); code != 0 {
+					t.Errorf("exit status %d: %s", code, out)
+				}
+				// converting back with the recorded format gives the original value
+				back := mustRun(t, "", "convert", "-type", tt.typ, "-in", converted, "-to", formatSerialized)
+				field := map[string]string{typeSecretKey: fieldSecretKey, typePublicKey: fieldPublicKey, typeSign: fieldSignature}[tt.typ]
+				original, _ := os.ReadFile(in)
+				if jsonField(t, back, field) != jsonField(t, string(original), field) {
+					t.Errorf("round trip gives %s", back)
+				}
+			})
+		}
+	}
+	bad := writeFile(t, dir, "bad.json", `{"secretKey": "01", "format": "base64"}`)
+	if _, code := runCmd(t, "", "sign", "-sk", bad, "-msg", "hello"); code != 2 {
+		t.Error("expected exit status 2 for an unsupported format")
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	dir := t.TempDir()
+	const n = 3
+	var keys, pops, sameSigs, distinctSigs []string
+	var distinct []string
+	for i := 0; i < n; i++ {
+		name := string(rune('a' + i))
+		key := writeFile(t, dir, "key-"+name+".json", mustRun(t, "", "keygen"))
+		keys = append(keys, key)
+		pops = append(pops, writeFile(t, dir, "pop-"+name+".json", mustRun(t, "", "pop", "-sk", key)))
+		sameSigs = append(sameSigs, writeFile(t, dir, "same-"+name+".json", mustRun(t, "", "sign", "-sk", key, "-msg", "vote")))
+		distinct = append(distinct, "message "+name)
+		distinctSigs = append(distinctSigs, writeFile(t, dir, "distinct-"+name+".json", mustRun(t, "", "sign", "-sk", key, "-msg", distinct[i])))
+	}
+	same := writeFile(t, dir, "same.json", mustRun(t, "", append([]string{"aggregate"}, sameSigs...)...))
+	agg := writeFile(t, dir, "distinct.json", mustRun(t, "", append([]string{"aggregate"}, distinctSigs...)...))
+	aggPub := writeFile(t, dir, "agg-pub.json", mustRun(t, "", append([]string{"aggregate", "-pub"}, keys...)...))
+	if out, code := runCmd(t, "", "verify", "-pk", aggPub, "-sig", same, "-msg", "vote"); code != 0 {
+		t.Errorf("aggregate does not verify under the aggregated public key: %s", out)
+	}
+	otherPop := writeFile(t, dir, "other-pop.json", mustRun(t, "", "pop", "-sk", writeFile(t, dir, "other.json", mustRun(t, "", "keygen"))))
+
+	flags := func(name string, values []string) []string {
+		var args []string
+		for _, v := range values {
+			args = append(args, "-"+name, v)
+		}
+		return args
+	}
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"distinct messages", append(append([]string{"-sig", agg}, flags("pk", keys)...), flags("msg", distinct)...), 0},
+		{"swapped messages", append(append([]string{"-sig", agg}, flags("pk", keys)...), flags("msg", []string{distinct[1], distinct[0], distinct[2]})...), 1},
+		{"duplicate messages", append(append([]string{"-sig", agg}, flags("pk", keys)...), flags("msg", []string{"vote", "vote", "other"})...), 2},
+		{"single message without pops", append([]string{"-sig", same, "-msg", "vote"}, flags("pk", keys)...), 0},
+		{"single message with pops", append(append([]string{"-sig", same, "-msg", "vote"}, flags("pk", keys)...), flags("pop", pops)...), 0},
+		{"single message with a bad pop", append(append([]string{"-sig", same, "-msg", "vote"}, flags("pk", keys)...), flags("pop", []string{pops[0], otherPop, pops[2]})...), 1},
+		{"single message with missing pops", append(append([]string{"-sig", same, "-msg", "vote"}, flags("pk", keys)...), flags("pop", pops[:2])...), 2},
+		{"pops with distinct messages", append(append(append([]string{"-sig", agg}, flags("pk", keys)...), flags("msg", distinct)...), flags("pop", pops)...), 2},
+		{"missing signer", append(append([]string{"-sig", same, "-msg", "vote"}, flags("pk", keys[:2])...), flags("pop", pops[:2])...), 1},
+		{"mismatched messages", append(append([]string{"-sig", agg}, flags("pk", keys)...), flags("msg", distinct[:2])...), 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if out, code := runCmd(t, "", append([]string{"aggregate-verify"}, tt.args...)...); code != tt.code {
+				t.Errorf("exit status %d, expected %d: %s", code, tt.code, out)
+			}
+		})
+	}
+}
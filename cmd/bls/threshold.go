@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+// Threshold ceremonies: a dealer splits a secret key into shares with split, members sign with
+// their share file, anyone combines k signature shares, and the group public key can be checked
+// against public key shares derived with pubshares.
+//
+// Ids are decimal strings and must be distinct and non-zero: the share of id 0 is the secret key.
+
+const (
+	fieldThreshold       = "threshold"
+	fieldMasterPublicKey = "masterPublicKey"
+)
+
+// shareOutput -- a key share of a committee member, written by split
+type shareOutput struct {
+	ID        string `json:"id"`
+	Threshold int    `json:"threshold"`
+	SecretKey string `json:"secretKey,omitempty"`
+	PublicKey string `json:"publicKey"`
+}
+
+// groupOutput -- the public parameters of a committee, written by split
+type groupOutput struct {
+	Threshold       int           `json:"threshold"`
+	PublicKey       string        `json:"publicKey"`
+	MasterPublicKey []string      `json:"masterPublicKey"`
+	Shares          []shareOutput `json:"shares,omitempty"`
+}
+
+func runSplit(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("split")
+	skPath := fs.String("sk", "", "secret key file, a random key is generated if empty")
+	k := fs.Int("k", 0, "threshold: number of shares needed to sign")
+	n := fs.Int("n", 0, "number of shares, with ids 1 to n")
+	idList := fs.String("ids", "", "comma separated decimal ids of the shares, instead of -n")
+	outDir := fs.String("out", "", "directory to write group.json and share-<id>.json to, instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	idVec, err := parseIDs(*idList, *n)
+	if err != nil {
+		return err
+	}
+	if *k <= 0 || *k > len(idVec) {
+		return fmt.Errorf("-k must be between 1 and the number of shares %d", len(idVec))
+	}
+	var sec bls.SecretKey
+	if *skPath != "" {
+		r := &inputReader{stdin: stdin}
+		s, err := r.secretKey(*skPath)
+		if err != nil {
+			return err
+		}
+		sec = *s
+	} else {
+		sec.SetByCSPRNG()
+	}
+
+	msk := sec.GetMasterSecretKey(*k)
+	mpk := bls.GetMasterPublicKey(msk)
+	group := groupOutput{
+		Threshold:       *k,
+		PublicKey:       sec.GetPublicKey().SerializeToHexStr(),
+		MasterPublicKey: make([]string, len(mpk)),
+	}
+	for i := range mpk {
+		group.MasterPublicKey[i] = mpk[i].SerializeToHexStr()
+	}
+	for i := range idVec {
+		var share bls.SecretKey
+		if err := share.Set(msk, &idVec[i]); err != nil {
+			return err
+		}
+		group.Shares = append(group.Shares, shareOutput{
+			ID:        idVec[i].GetDecString(),
+			Threshold: *k,
+			SecretKey: share.SerializeToHexStr(),
+			PublicKey: share.GetPublicKey().SerializeToHexStr(),
+		})
+	}
+	if *outDir == "" {
+		return writeJSON(stdout, group)
+	}
+
+	if err := os.MkdirAll(*outDir, 0700); err != nil {
+		return err
+	}
+	shares := group.Shares
+	group.Shares = nil
+	if err := writeJSONFile(filepath.Join(*outDir, "group.json"), group, 0644); err != nil {
+		return err
+	}
+	files := make([]string, len(shares))
+	for i := range shares {
+		files[i] = filepath.Join(*outDir, "share-"+shares[i].ID+".json")
+		if err := writeJSONFile(files[i], shares[i], 0600); err != nil {
+			return err
+		}
+	}
+	return writeJSON(stdout, map[string]interface{}{"group": filepath.Join(*outDir, "group.json"), "shares": files})
+}
+
+func runPubshares(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("pubshares")
+	groupPath := fs.String("group", "-", "group file holding the master public key, as written by split")
+	n := fs.Int("n", 0, "derive the public key shares of ids 1 to n")
+	idList := fs.String("ids", "", "comma separated decimal ids, instead of -n")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	idVec, err := parseIDs(*idList, *n)
+	if err != nil {
+		return err
+	}
+	r := &inputReader{stdin: stdin}
+	var mpkHex []string
+	if found, err := r.field(*groupPath, fieldMasterPublicKey, &mpkHex); err != nil {
+		return err
+	} else if !found || len(mpkHex) == 0 {
+		return fmt.Errorf("%s: no %q field", *groupPath, fieldMasterPublicKey)
+	}
+	mpk := make([]bls.PublicKey, len(mpkHex))
+	for i := range mpkHex {
+		if err := mpk[i].DeserializeHexStr(mpkHex[i]); err != nil {
+			return fmt.Errorf("%s: master public key %d: %v", *groupPath, i, err)
+		}
+	}
+	group := groupOutput{
+		Threshold:       len(mpk),
+		PublicKey:       mpk[0].SerializeToHexStr(),
+		MasterPublicKey: mpkHex,
+	}
+	for i := range idVec {
+		var pub bls.PublicKey
+		if err := pub.Set(mpk, &idVec[i]); err != nil {
+			return err
+		}
+		group.Shares = append(group.Shares, shareOutput{
+			ID:        idVec[i].GetDecString(),
+			Threshold: len(mpk),
+			PublicKey: pub.SerializeToHexStr(),
+		})
+	}
+	return writeJSON(stdout, group)
+}
+
+func runCombine(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("combine")
+	k := fs.Int("k", 0, "threshold: the first k shares with distinct ids are combined")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	r := &inputReader{stdin: stdin}
+	idVec, paths, err := readShareIDs(r, fs.Args(), *k)
+	if err != nil {
+		return err
+	}
+	signVec := make([]bls.Sign, len(paths))
+	for i, path := range paths {
+		sig, err := r.sign(path, fieldSignature)
+		if err != nil {
+			return err
+		}
+		signVec[i] = *sig
+	}
+	var sig bls.Sign
+	if err := sig.Recover(signVec, idVec); err != nil {
+		return err
+	}
+	return writeJSON(stdout, map[string]string{fieldSignature: sig.SerializeToHexStr()})
+}
+
+func runRecoverPubkey(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("recover-pubkey")
+	k := fs.Int("k", 0, "threshold: the first k shares with distinct ids are combined")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	r := &inputReader{stdin: stdin}
+	idVec, paths, err := readShareIDs(r, fs.Args(), *k)
+	if err != nil {
+		return err
+	}
+	pubVec := make([]bls.PublicKey, len(paths))
+	for i, path := range paths {
+		pub, err := r.publicKey(path)
+		if err != nil {
+			return err
+		}
+		pubVec[i] = *pub
+	}
+	var pub bls.PublicKey
+	if err := pub.Recover(pubVec, idVec); err != nil {
+		return err
+	}
+	return writeJSON(stdout, map[string]string{fieldPublicKey: pub.SerializeToHexStr()})
+}
+
+// parseIDs returns the ids of a comma separated list, or 1 to n if the list is empty
+func parseIDs(list string, n int) ([]bls.ID, error) {
+	var idVec []bls.ID
+	switch {
+	case list != "" && n == 0:
+		for _, s := range strings.Split(list, ",") {
+			var id bls.ID
+			if err := id.SetDecString(strings.TrimSpace(s)); err != nil {
+				return nil, fmt.Errorf("bad id %q: %v", s, err)
+			}
+			idVec = append(idVec, id)
+		}
+	case list == "" && n > 0:
+		idVec = make([]bls.ID, n)
+		for i := range idVec {
+			if err := idVec[i].SetDecString(strconv.Itoa(i + 1)); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, errors.New("exactly one of -n and -ids is required")
+	}
+	if _, err := bls.NewLagrangeBasis(idVec); err != nil {
+		return nil, err
+	}
+	return idVec, nil
+}
+
+// readShareIDs returns the ids and paths of the first k share files with distinct ids
+func readShareIDs(r *inputReader, paths []string, k int) ([]bls.ID, []string, error) {
+	if k <= 0 {
+		return nil, nil, errors.New("-k is required")
+	}
+	idVec := make([]bls.ID, 0, k)
+	used := make([]string, 0, k)
+	seen := make(map[string]bool, k)
+	for _, path := range paths {
+		if len(idVec) == k {
+			break
+		}
+		id, err := r.shareID(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if id == nil {
+			return nil, nil, fmt.Errorf("%s: no %q field", path, fieldID)
+		}
+		if id.GetDecString() == "0" {
+			return nil, nil, fmt.Errorf("%s: zero id", path)
+		}
+		if seen[id.GetDecString()] {
+			continue
+		}
+		seen[id.GetDecString()] = true
+		idVec = append(idVec, *id)
+		used = append(used, path)
+	}
+	if len(idVec) < k {
+		return nil, nil, fmt.Errorf("%d shares with distinct ids, need %d", len(idVec), k)
+	}
+	return idVec, used, nil
+}
+
+// shareID returns the id of the share in the file at path, or nil if it has none
+func (r *inputReader) shareID(path string) (*bls.ID, error) {
+	buf, err := r.read(path)
+	if err != nil {
+		return nil, err
+	}
+	if buf = bytes.TrimSpace(buf); len(buf) == 0 || buf[0] != '{' {
+		return nil, nil
+	}
+	var s string
+	if found, err := r.field(path, fieldID, &s); err != nil || !found {
+		return nil, err
+	}
+	id := new(bls.ID)
+	if err := id.SetDecString(s); err != nil {
+		return nil, fmt.Errorf("%s: bad id %q: %v", path, s, err)
+	}
+	return id, nil
+}
+
+// writeJSONFile writes v as indented JSON to a new file at path
+func writeJSONFile(path string, v interface{}, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(f, v); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
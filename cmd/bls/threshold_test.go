@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// publicKeyOf returns the serialized public key of the file at path
+func publicKeyOf(t *testing.T, path string) string {
+	t.Helper()
+	return jsonField(t, mustRun(t, "", "convert", "-type", typePublicKey, "-in", path, "-to", formatSerialized), fieldPublicKey)
+}
+
+func TestThreshold(t *testing.T) {
+	const k, n = 3, 5
+	dir := t.TempDir()
+	key := writeFile(t, dir, "key.json", mustRun(t, "", "keygen"))
+	ceremony := filepath.Join(dir, "ceremony")
+	mustRun(t, "", "split", "-sk", key, "-k", strconv.Itoa(k), "-n", strconv.Itoa(n), "-out", ceremony)
+	group := filepath.Join(ceremony, "group.json")
+	if jsonField(t, mustRun(t, "", "pubkey", "-sk", key), fieldPublicKey) != publicKeyOf(t, group) {
+		t.Fatal("group public key is not the public key of the split key")
+	}
+
+	shares := make([]string, n)
+	sigs := make([]string, n)
+	for i := range shares {
+		id := strconv.Itoa(i + 1)
+		shares[i] = filepath.Join(ceremony, "share-"+id+".json")
+		sigs[i] = writeFile(t, dir, "sig-"+id+".json", mustRun(t, "", "sign", "-sk", shares[i], "-msg", "hello"))
+		if out, code := runCmd(t, "", "verify", "-pk", shares[i], "-sig", sigs[i], "-msg", "hello"); code != 0 {
+			t.Errorf("signature share %s does not verify: %s", id, out)
+		}
+	}
+	tests := []struct {
+		name string
+		k    int
+		sigs []string
+		code int
+	}{
+		{"first shares", k, sigs[:k], 0},
+		{"last shares", k, sigs[n-k:], 0},
+		{"duplicate share", k, []string{sigs[0], sigs[0], sigs[3], sigs[4]}, 0},
+		{"all shares", k, sigs, 0},
+		{"too few shares", k, sigs[:k-1], 2},
+		{"duplicate shares only", k, []string{sigs[1], sigs[1], sigs[2]}, 2},
+		{"below threshold", k - 1, sigs[:k-1], 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, code := runCmd(t, "", append([]string{"combine", "-k", strconv.Itoa(tt.k)}, tt.sigs...)...)
+			if code == 0 {
+				out, code = runCmd(t, out, "verify", "-pk", group, "-sig", "-", "-msg", "hello")
+			}
+			if code != tt.code {
+				t.Errorf("exit status %d, expected %d: %s", code, tt.code, out)
+			}
+		})
+	}
+
+	// public key shares derived from the group file match the share files
+	var derived groupOutput
+	if err := json.Unmarshal([]byte(mustRun(t, "", "pubshares", "-group", group, "-n", strconv.Itoa(n))), &derived); err != nil {
+		t.Fatal(err)
+	}
+	if len(derived.Shares) != n {
+		t.Fatalf("%d public key shares", len(derived.Shares))
+	}
+	pubs := make([]string, n)
+	for i, share := range derived.Shares {
+		if share.ID != strconv.Itoa(i+1) || share.PublicKey != publicKeyOf(t, shares[i]) {
+			t.Errorf("public key share %d does not match its share file", i+1)
+		}
+		buf, err := json.Marshal(share)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubs[i] = writeFile(t, dir, "pub-"+share.ID+".json", string(buf))
+	}
+	groupPub := publicKeyOf(t, group)
+	recovered := mustRun(t, "", append([]string{"recover-pubkey", "-k", strconv.Itoa(k)}, pubs[1:]...)...)
+	if jsonField(t, recovered, fieldPublicKey) != groupPub {
+		t.Error("recovered public key is not the group public key")
+	}
+	recovered = mustRun(t, "", append([]string{"recover-pubkey", "-k", strconv.Itoa(k - 1)}, pubs...)...)
+	if jsonField(t, recovered, fieldPublicKey) == groupPub {
+		t.Error("public key recovered from k-1 shares")
+	}
+	if _, code := runCmd(t, "", append([]string{"recover-pubkey", "-k", strconv.Itoa(k)}, pubs[:k-1]...)...); code != 2 {
+		t.Error("expected exit status 2 for too few public key shares")
+	}
+}
@@ -0,0 +1,115 @@
+package bls
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ---------------- EIP-2333 Key Derivation --------------------
+// Hierarchical deterministic derivation of BLS12-381 secret keys as specified by
+// https://eips.ethereum.org/EIPS/eip-2333 with paths as specified by
+// https://eips.ethereum.org/EIPS/eip-2334
+
+// EIP2334Purpose -- the purpose level of EIP-2334 paths
+const EIP2334Purpose = 12381
+
+// lamportChunks -- number of 32 byte chunks in each half of a lamport secret key
+const lamportChunks = 255
+
+// DeriveMasterSK derives the master secret key from a seed of at least 32 bytes
+func DeriveMasterSK(seed []byte) (SecretKey, error) {
+	if len(seed) < 32 {
+		return SecretKey{}, fmt.Errorf("err DeriveMasterSK:seed must be at least 32 bytes, got %d", len(seed))
+	}
+	return hkdfModR(seed, nil), nil
+}
+
+// DeriveChildSK derives the child secret key at index from parent
+func DeriveChildSK(parent *SecretKey, index uint32) SecretKey {
+	return hkdfModR(parentSKToLamportPK(parent, index), nil)
+}
+
+// DeriveSKFromPath derives the secret key at path (e.g. m/12381/3600/0/0) from seed
+func DeriveSKFromPath(seed []byte, path string) (SecretKey, error) {
+	indices, err := ParsePath(path)
+	if err != nil {
+		return SecretKey{}, err
+	}
+	sec, err := DeriveMasterSK(seed)
+	if err != nil {
+		return SecretKey{}, err
+	}
+	for _, index := range indices {
+		sec = DeriveChildSK(&sec, index)
+	}
+	return sec, nil
+}
+
+// ParsePath parses a path such as m/12381/3600/0/0 into its child indices
+// "m" alone is the master key and yields no indices. The levels are not checked against
+// EIP-2334, see ValidatePath
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("err ParsePath:path must start with m: %q", path)
+	}
+	indices := make([]uint32, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		index, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("err ParsePath:bad index %q in %q", p, path)
+		}
+		indices = append(indices, uint32(index))
+	}
+	return indices, nil
+}
+
+// ValidatePath checks that path is the master key "m" or an EIP-2334 path, whose first level
+// is the purpose EIP2334Purpose
+func ValidatePath(path string) error {
+	indices, err := ParsePath(path)
+	if err != nil {
+		return err
+	}
+	if len(indices) > 0 && indices[0] != EIP2334Purpose {
+		return fmt.Errorf("err ValidatePath:purpose must be %d, got %d in %q", EIP2334Purpose, indices[0], path)
+	}
+	return nil
+}
+
+// parentSKToLamportPK returns the compressed lamport public key of parent at index
+func parentSKToLamportPK(parent *SecretKey, index uint32) []byte {
+	salt := make([]byte, 4)
+	binary.BigEndian.PutUint32(salt, index)
+	ikm := secretKeyToBytes32(parent)
+	notIkm := make([]byte, len(ikm))
+	for i := range ikm {
+		notIkm[i] = ^ikm[i]
+	}
+	h := sha256.New()
+	for _, lamport := range [][]byte{ikmToLamportSK(ikm, salt), ikmToLamportSK(notIkm, salt)} {
+		for i := 0; i < lamportChunks; i++ {
+			chunk := sha256.Sum256(lamport[i*32 : (i+1)*32])
+			h.Write(chunk[:])
+		}
+	}
+	return h.Sum(nil)
+}
+
+// ikmToLamportSK returns the 255 concatenated 32 byte chunks of a lamport secret key
+func ikmToLamportSK(ikm []byte, salt []byte) []byte {
+	return hkdfExpand(hkdfExtract(salt, ikm), nil, 32*lamportChunks)
+}
+
+// secretKeyToBytes32 returns I2OSP(sec, 32), the 32 byte big-endian encoding of sec
+func secretKeyToBytes32(sec *SecretKey) []byte {
+	le := sec.GetLittleEndian()
+	out := make([]byte, 32)
+	for i := 0; i < len(le) && i < 32; i++ {
+		out[31-i] = le[i]
+	}
+	return out
+}
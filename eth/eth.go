@@ -0,0 +1,241 @@
+// Package eth implements the BLS signatures of the Ethereum consensus layer, the proof of possession
+// scheme BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_ of the IETF BLS signature draft, with the pairing
+// of the bls package.
+//
+// The groups are swapped with respect to the bls package: public keys are in G1, derived from the
+// standard generator, and signatures are in G2, hashed with the hash to curve of RFC 9380 (see
+// HashToG2). Secret keys are 32-byte big-endian scalars and points use the compressed ZCash
+// encoding, whatever the SetETHserialization mode. A SecretKey of the bls package, for instance
+// one derived with EIP-2333 or read from an EIP-2335 keystore, is converted with FromSecretKey.
+//
+// As in the Ethereum specification, Deserialize accepts the point at infinity, while the verify
+// functions reject public keys at infinity (KeyValidate) and AggregatePublicKeys rejects them.
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/internal/bls12381"
+)
+
+// Sizes of the encodings
+const (
+	SecretKeySize = 32
+	PublicKeySize = bls12381.G1Size
+	SignatureSize = bls12381.G2Size
+)
+
+// ErrNoInputs is returned when aggregating nothing
+var ErrNoInputs = errors.New("err eth:nothing to aggregate")
+
+// g1Generator -- the standard generator of G1
+var g1Generator = func() (g bls.G1) {
+	x, _ := new(big.Int).SetString("17f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb", 16)
+	y, _ := new(big.Int).SetString("08b3f481e3aaa0f1a09e30ed741d8ae4fcf5e095d5d00af600db18cb2c04b3edd03cc744a2888ae40caa232946c5e7e1", 16)
+	if err := bls12381.SetG1Affine(&g, x, y); err != nil {
+		panic(err)
+	}
+	return g
+}()
+
+// SecretKey --
+type SecretKey struct {
+	v bls.Fr
+}
+
+// PublicKey -- a point of G1
+type PublicKey struct {
+	v bls.G1
+}
+
+// Signature -- a point of G2
+type Signature struct {
+	v bls.G2
+}
+
+// FromSecretKey returns the Ethereum key of the scalar of sec
+func FromSecretKey(sec *bls.SecretKey) SecretKey {
+	return SecretKey{*bls.CastFromSecretKey(sec)}
+}
+
+// SetByCSPRNG --
+func (sec *SecretKey) SetByCSPRNG() {
+	for sec.v.SetByCSPRNG(); sec.v.IsZero(); {
+		sec.v.SetByCSPRNG()
+	}
+}
+
+// Deserialize sets sec from its big-endian encoding, which must be in [1, r)
+func (sec *SecretKey) Deserialize(buf []byte) error {
+	if len(buf) != SecretKeySize {
+		return fmt.Errorf("err eth:bad secret key size %d", len(buf))
+	}
+	x := new(big.Int).SetBytes(buf)
+	if x.Sign() == 0 || x.Cmp(bls12381.R) >= 0 {
+		return fmt.Errorf("err eth:secret key out of range")
+	}
+	return sec.v.SetString(x.String(), 10)
+}
+
+// Serialize returns the big-endian encoding of sec
+func (sec *SecretKey) Serialize() []byte {
+	x, ok := new(big.Int).SetString(sec.v.GetString(10), 10)
+	if !ok {
+		panic("err eth:bad secret key " + sec.v.GetString(10))
+	}
+	return x.FillBytes(make([]byte, SecretKeySize))
+}
+
+// PublicKey -- sec * G1
+func (sec *SecretKey) PublicKey() *PublicKey {
+	pub := new(PublicKey)
+	bls.G1Mul(&pub.v, &g1Generator, &sec.v)
+	return pub
+}
+
+// Sign returns sec * HashToG2(msg, DST)
+func (sec *SecretKey) Sign(msg []byte) *Signature {
+	h, err := HashToG2(msg, []byte(DST))
+	if err != nil {
+		panic(err)
+	}
+	sig := new(Signature)
+	bls.G2Mul(&sig.v, &h, &sec.v)
+	return sig
+}
+
+// Deserialize sets pub from its compressed encoding, checking that it is in the subgroup
+func (pub *PublicKey) Deserialize(buf []byte) error {
+	return bls12381.DecodeG1(buf, &pub.v)
+}
+
+// Serialize returns the compressed encoding of pub
+func (pub *PublicKey) Serialize() []byte {
+	out := bls12381.EncodeG1(&pub.v)
+	return out[:]
+}
+
+// IsEqual --
+func (pub *PublicKey) IsEqual(rhs *PublicKey) bool {
+	return pub.v.IsEqual(&rhs.v)
+}
+
+// IsValid -- KeyValidate: pub is not the point at infinity, subgroup membership is checked by Deserialize
+func (pub *PublicKey) IsValid() bool {
+	return !pub.v.IsZero()
+}
+
+// Deserialize sets sig from its compressed encoding, checking that it is in the subgroup
+func (sig *Signature) Deserialize(buf []byte) error {
+	return bls12381.DecodeG2(buf, &sig.v)
+}
+
+// Serialize returns the compressed encoding of sig
+func (sig *Signature) Serialize() []byte {
+	out := bls12381.EncodeG2(&sig.v)
+	return out[:]
+}
+
+// IsEqual --
+func (sig *Signature) IsEqual(rhs *Signature) bool {
+	return sig.v.IsEqual(&rhs.v)
+}
+
+// Verify checks sig on msg under pub
+func Verify(pub *PublicKey, msg []byte, sig *Signature) bool {
+	if !pub.IsValid() {
+		return false
+	}
+	return coreVerify(&pub.v, msg, sig)
+}
+
+// Aggregate returns the sum of sigs
+func Aggregate(sigs []Signature) (*Signature, error) {
+	if len(sigs) == 0 {
+		return nil, ErrNoInputs
+	}
+	agg := new(Signature)
+	for i := range sigs {
+		bls.G2Add(&agg.v, &agg.v, &sigs[i].v)
+	}
+	return agg, nil
+}
+
+// AggregatePublicKeys -- eth_aggregate_pubkeys, the sum of pubs, none of which may be at infinity
+func AggregatePublicKeys(pubs []PublicKey) (*PublicKey, error) {
+	if len(pubs) == 0 {
+		return nil, ErrNoInputs
+	}
+	agg := new(PublicKey)
+	for i := range pubs {
+		if !pubs[i].IsValid() {
+			return nil, fmt.Errorf("err eth:public key %d at infinity", i)
+		}
+		bls.G1Add(&agg.v, &agg.v, &pubs[i].v)
+	}
+	return agg, nil
+}
+
+// FastAggregateVerify checks the aggregate sig of msg signed by all of pubs
+// The public keys must come with a verified proof of possession.
+func FastAggregateVerify(pubs []PublicKey, msg []byte, sig *Signature) bool {
+	agg, err := AggregatePublicKeys(pubs)
+	if err != nil {
+		return false
+	}
+	return coreVerify(&agg.v, msg, sig)
+}
+
+// EthFastAggregateVerify -- eth_fast_aggregate_verify, FastAggregateVerify which also accepts no
+// public keys with the signature at infinity
+func EthFastAggregateVerify(pubs []PublicKey, msg []byte, sig *Signature) bool {
+	if len(pubs) == 0 && sig.v.IsZero() {
+		return true
+	}
+	return FastAggregateVerify(pubs, msg, sig)
+}
+
+// AggregateVerify checks the aggregate sig of msgs[i] signed by pubs[i]
+//
+//	e(G1, sig) = prod_i e(pubs[i], HashToG2(msgs[i], DST))
+func AggregateVerify(pubs []PublicKey, msgs [][]byte, sig *Signature) bool {
+	if len(pubs) == 0 || len(pubs) != len(msgs) {
+		return false
+	}
+	var e, t bls.GT
+	var neg bls.G1
+	bls.G1Neg(&neg, &g1Generator)
+	bls.MillerLoop(&e, &neg, &sig.v)
+	for i := range pubs {
+		if !pubs[i].IsValid() {
+			return false
+		}
+		h, err := HashToG2(msgs[i], []byte(DST))
+		if err != nil {
+			return false
+		}
+		bls.MillerLoop(&t, &pubs[i].v, &h)
+		bls.GTMul(&e, &e, &t)
+	}
+	bls.FinalExp(&e, &e)
+	return e.IsOne()
+}
+
+// coreVerify -- e(G1, sig) = e(pub, HashToG2(msg, DST))
+func coreVerify(pub *bls.G1, msg []byte, sig *Signature) bool {
+	h, err := HashToG2(msg, []byte(DST))
+	if err != nil {
+		return false
+	}
+	var e, t bls.GT
+	var neg bls.G1
+	bls.G1Neg(&neg, &g1Generator)
+	bls.MillerLoop(&e, &neg, &sig.v)
+	bls.MillerLoop(&t, pub, &h)
+	bls.GTMul(&e, &e, &t)
+	bls.FinalExp(&e, &e)
+	return e.IsOne()
+}
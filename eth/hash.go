@@ -0,0 +1,292 @@
+package eth
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/internal/bls12381"
+)
+
+// ---------------- Hash to G2 --------------------
+// hash_to_curve of RFC 9380 for the suite BLS12381G2_XMD:SHA-256_SSWU_RO_:
+//
+//	u0, u1 = hash_to_field(msg, 2)       expand_message_xmd with SHA-256
+//	Q0, Q1 = iso_map(map_to_curve(u))    simplified SWU on the 3-isogenous curve E2'
+//	P      = h_eff * (Q0 + Q1)            clear_cofactor with the endomorphism psi (appendix G.3)
+//
+// Q0 and Q1 are not in the subgroup, which mcl refuses, so the points are added and the cofactor
+// is cleared in Jacobian coordinates with math/big before P is handed to mcl.
+
+// DST -- domain separation tag of the proof of possession scheme used by Ethereum
+const DST = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+
+// hashToFieldSize -- L of hash_to_field, bytes per base field element
+const hashToFieldSize = 64
+
+var (
+	// E2': y^2 = x^3 + A * x + B
+	isoA = bls12381.NewFp2(0, 240)
+	isoB = bls12381.NewFp2(1012, 1012)
+	// sswuZ -- Z of the simplified SWU map
+	sswuZ = bls12381.NewFp2(-2, -1)
+
+	// coefficients of the rational maps of the 3-isogeny E2' -> E2, constant term first
+	isoXNum = []bls12381.Fp2{
+		hexFp2("5c759507e8e333ebb5b7a9a47d7ed8532c52d39fd3a042a88b58423c50ae15d5c2638e343d9c71c6238aaaaaaaa97d6", "5c759507e8e333ebb5b7a9a47d7ed8532c52d39fd3a042a88b58423c50ae15d5c2638e343d9c71c6238aaaaaaaa97d6"),
+		hexFp2("0", "11560bf17baa99bc32126fced787c88f984f87adf7ae0c7f9a208c6b4f20a4181472aaa9cb8d555526a9ffffffffc71a"),
+		hexFp2("11560bf17baa99bc32126fced787c88f984f87adf7ae0c7f9a208c6b4f20a4181472aaa9cb8d555526a9ffffffffc71e", "8ab05f8bdd54cde190937e76bc3e447cc27c3d6fbd7063fcd104635a790520c0a395554e5c6aaaa9354ffffffffe38d"),
+		hexFp2("171d6541fa38ccfaed6dea691f5fb614cb14b4e7f4e810aa22d6108f142b85757098e38d0f671c7188e2aaaaaaaa5ed1", "0"),
+	}
+	isoXDen = []bls12381.Fp2{
+		hexFp2("0", "1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaa63"),
+		hexFp2("c", "1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaa9f"),
+		hexFp2("1", "0"),
+	}
+	isoYNum = []bls12381.Fp2{
+		hexFp2("1530477c7ab4113b59a4c18b076d11930f7da5d4a07f649bf54439d87d27e500fc8c25ebf8c92f6812cfc71c71c6d706", "1530477c7ab4113b59a4c18b076d11930f7da5d4a07f649bf54439d87d27e500fc8c25ebf8c92f6812cfc71c71c6d706"),
+		hexFp2("0", "5c759507e8e333ebb5b7a9a47d7ed8532c52d39fd3a042a88b58423c50ae15d5c2638e343d9c71c6238aaaaaaaa97be"),
+		hexFp2("11560bf17baa99bc32126fced787c88f984f87adf7ae0c7f9a208c6b4f20a4181472aaa9cb8d555526a9ffffffffc71c", "8ab05f8bdd54cde190937e76bc3e447cc27c3d6fbd7063fcd104635a790520c0a395554e5c6aaaa9354ffffffffe38f"),
+		hexFp2("124c9ad43b6cf79bfbf7043de3811ad0761b0f37a1e26286b0e977c69aa274524e79097a56dc4bd9e1b371c71c718b10", "0"),
+	}
+	isoYDen = []bls12381.Fp2{
+		hexFp2("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffa8fb", "1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffa8fb"),
+		hexFp2("0", "1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffa9d3"),
+		hexFp2("12", "1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaa99"),
+		hexFp2("1", "0"),
+	}
+
+	// blsX -- |x| of the curve parameter x = -0xd201000000010000
+	blsX = new(big.Int).SetUint64(0xd201000000010000)
+
+	// psi(x, y) = (conj(x) * psiX, conj(y) * psiY)
+	psiX = bls12381.NewFp2(1, 1).Exp(new(big.Int).Div(new(big.Int).Sub(bls12381.P, big.NewInt(1)), big.NewInt(3))).Inverse()
+	psiY = bls12381.NewFp2(1, 1).Exp(new(big.Int).Rsh(bls12381.P, 1)).Inverse()
+)
+
+func hexFp2(c0, c1 string) bls12381.Fp2 {
+	x0, ok0 := new(big.Int).SetString(c0, 16)
+	x1, ok1 := new(big.Int).SetString(c1, 16)
+	if !ok0 || !ok1 {
+		panic("err eth:bad constant")
+	}
+	return bls12381.Fp2{C0: x0, C1: x1}
+}
+
+// HashToG2 hashes msg to a point of G2 with the domain separation tag dst as specified by
+// hash_to_curve of RFC 9380, dst must have between 1 and 255 bytes
+func HashToG2(msg []byte, dst []byte) (p bls.G2, err error) {
+	buf, err := expandMessageXMD(msg, dst, 2*2*hashToFieldSize)
+	if err != nil {
+		return p, err
+	}
+	var sum g2Jac
+	for i := 0; i < 2; i++ {
+		e := buf[2*i*hashToFieldSize:]
+		u := bls12381.Fp2{C0: fieldElement(e[:hashToFieldSize]), C1: fieldElement(e[hashToFieldSize : 2*hashToFieldSize])}
+		x, y, ok := isoMap(mapToCurve(u))
+		if ok {
+			sum = sum.add(g2Jac{x, y, bls12381.NewFp2(1, 0)})
+		}
+	}
+	x, y, ok := sum.clearCofactor().affine()
+	if !ok {
+		return p, nil
+	}
+	if err := bls12381.SetG2Affine(&p, x, y); err != nil {
+		return p, fmt.Errorf("err eth:hash to G2:%v", err)
+	}
+	return p, nil
+}
+
+// expandMessageXMD -- expand_message_xmd of RFC 9380 with SHA-256
+func expandMessageXMD(msg []byte, dst []byte, size int) ([]byte, error) {
+	ell := (size + sha256.Size - 1) / sha256.Size
+	if len(dst) == 0 || len(dst) > 255 || ell > 255 {
+		return nil, fmt.Errorf("err eth:bad expand_message_xmd dst of %d bytes or size %d", len(dst), size)
+	}
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+	h := sha256.New()
+	h.Write(make([]byte, sha256.BlockSize))
+	h.Write(msg)
+	h.Write([]byte{byte(size >> 8), byte(size), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	out := make([]byte, 0, ell*sha256.Size)
+	bi := make([]byte, sha256.Size)
+	for i := 1; i <= ell; i++ {
+		for j := range bi {
+			bi[j] ^= b0[j] // b_1 = H(b_0 | 1 | dst'), b_i = H(b_0 xor b_(i-1) | i | dst')
+		}
+		h.Reset()
+		h.Write(bi)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(bi[:0])
+		out = append(out, bi...)
+	}
+	return out[:size], nil
+}
+
+// fieldElement -- the big-endian buf mod p
+func fieldElement(buf []byte) *big.Int {
+	x := new(big.Int).SetBytes(buf)
+	return x.Mod(x, bls12381.P)
+}
+
+// mapToCurve -- the simplified SWU map to E2' of section 6.6.2 of RFC 9380
+func mapToCurve(u bls12381.Fp2) (x, y bls12381.Fp2) {
+	zu2 := sswuZ.Mul(u.Square())
+	tv1 := zu2.Square().Add(zu2).Inverse()
+	if tv1.IsZero() {
+		x = isoB.Mul(sswuZ.Mul(isoA).Inverse())
+	} else {
+		x = isoB.Neg().Mul(isoA.Inverse()).Mul(tv1.Add(bls12381.NewFp2(1, 0)))
+	}
+	y, ok := isoCurve(x).Sqrt()
+	if !ok {
+		x = zu2.Mul(x)
+		y, _ = isoCurve(x).Sqrt()
+	}
+	if u.Sgn0() != y.Sgn0() {
+		y = y.Neg()
+	}
+	return x, y
+}
+
+// isoCurve -- x^3 + A * x + B on E2'
+func isoCurve(x bls12381.Fp2) bls12381.Fp2 {
+	return x.Square().Mul(x).Add(isoA.Mul(x)).Add(isoB)
+}
+
+// isoMap -- the 3-isogeny E2' -> E2, ok is false for the point at infinity
+func isoMap(x, y bls12381.Fp2) (bls12381.Fp2, bls12381.Fp2, bool) {
+	xDen := polynomial(isoXDen, x)
+	yDen := polynomial(isoYDen, x)
+	if xDen.IsZero() || yDen.IsZero() {
+		return x, y, false
+	}
+	return polynomial(isoXNum, x).Mul(xDen.Inverse()), y.Mul(polynomial(isoYNum, x)).Mul(yDen.Inverse()), true
+}
+
+// polynomial -- sum_i c[i] * x^i
+func polynomial(c []bls12381.Fp2, x bls12381.Fp2) bls12381.Fp2 {
+	y := c[len(c)-1]
+	for i := len(c) - 2; i >= 0; i-- {
+		y = y.Mul(x).Add(c[i])
+	}
+	return y
+}
+
+// g2Jac -- (X / Z^2, Y / Z^3) on E2: y^2 = x^3 + 4 (1 + u), the zero value is the point at infinity
+type g2Jac struct {
+	x, y, z bls12381.Fp2
+}
+
+func (p g2Jac) isZero() bool {
+	return p.z.C0 == nil || p.z.IsZero()
+}
+
+// dbl -- dbl-2009-l for a = 0
+func (p g2Jac) dbl() g2Jac {
+	if p.isZero() {
+		return p
+	}
+	a := p.x.Square()
+	b := p.y.Square()
+	c := b.Square()
+	d := p.x.Add(b).Square().Sub(a).Sub(c)
+	d = d.Add(d)
+	e := a.Add(a).Add(a)
+	x := e.Square().Sub(d).Sub(d)
+	c8 := c.Add(c)
+	c8 = c8.Add(c8)
+	c8 = c8.Add(c8)
+	z := p.y.Mul(p.z)
+	return g2Jac{x, e.Mul(d.Sub(x)).Sub(c8), z.Add(z)}
+}
+
+// add -- add-2007-bl
+func (p g2Jac) add(q g2Jac) g2Jac {
+	if p.isZero() {
+		return q
+	}
+	if q.isZero() {
+		return p
+	}
+	z1z1 := p.z.Square()
+	z2z2 := q.z.Square()
+	u1 := p.x.Mul(z2z2)
+	u2 := q.x.Mul(z1z1)
+	s1 := p.y.Mul(q.z).Mul(z2z2)
+	s2 := q.y.Mul(p.z).Mul(z1z1)
+	h := u2.Sub(u1)
+	r := s2.Sub(s1)
+	if h.IsZero() {
+		if r.IsZero() {
+			return p.dbl()
+		}
+		return g2Jac{}
+	}
+	r = r.Add(r)
+	i := h.Add(h).Square()
+	j := h.Mul(i)
+	v := u1.Mul(i)
+	x := r.Square().Sub(j).Sub(v).Sub(v)
+	s1j := s1.Mul(j)
+	y := r.Mul(v.Sub(x)).Sub(s1j).Sub(s1j)
+	z := p.z.Add(q.z).Square().Sub(z1z1).Sub(z2z2).Mul(h)
+	return g2Jac{x, y, z}
+}
+
+func (p g2Jac) neg() g2Jac {
+	if p.isZero() {
+		return p
+	}
+	return g2Jac{p.x, p.y.Neg(), p.z}
+}
+
+// mulX -- x * p for the negative curve parameter x, by double and add
+func (p g2Jac) mulX() g2Jac {
+	var q g2Jac
+	for i := blsX.BitLen() - 1; i >= 0; i-- {
+		q = q.dbl()
+		if blsX.Bit(i) == 1 {
+			q = q.add(p)
+		}
+	}
+	return q.neg()
+}
+
+// psi -- the untwist-Frobenius-twist endomorphism, conj commutes with the Jacobian coordinates
+func (p g2Jac) psi() g2Jac {
+	if p.isZero() {
+		return p
+	}
+	return g2Jac{conj(p.x).Mul(psiX), conj(p.y).Mul(psiY), conj(p.z)}
+}
+
+func conj(x bls12381.Fp2) bls12381.Fp2 {
+	return bls12381.Fp2{C0: x.C0, C1: new(big.Int).Mod(new(big.Int).Neg(x.C1), bls12381.P)}
+}
+
+// clearCofactor -- h_eff * p = (x^2 - x - 1) * p + (x - 1) * psi(p) + psi(psi(2 * p))
+func (p g2Jac) clearCofactor() g2Jac {
+	t1 := p.mulX()
+	t2 := p.psi()
+	t3 := p.dbl().psi().psi().add(t2.neg())
+	t2 = t1.add(t2).mulX()
+	return t3.add(t2).add(t1.neg()).add(p.neg())
+}
+
+// affine -- (X / Z^2, Y / Z^3), ok is false for the point at infinity
+func (p g2Jac) affine() (x, y bls12381.Fp2, ok bool) {
+	if p.isZero() {
+		return x, y, false
+	}
+	zInv := p.z.Inverse()
+	zInv2 := zInv.Square()
+	return p.x.Mul(zInv2), p.y.Mul(zInv2).Mul(zInv), true
+}
@@ -1 +1,8 @@
 module github.com/spacemeshos/go-bls
+
+go 1.20
+
+require (
+	golang.org/x/crypto v0.31.0
+	golang.org/x/text v0.21.0
+)
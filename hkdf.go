@@ -0,0 +1,64 @@
+package bls
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// keyGenSalt -- initial salt of HKDF_mod_r (EIP-2333) and KeyGen (IETF BLS signature draft)
+const keyGenSalt = "BLS-SIG-KEYGEN-SALT-"
+
+// hkdfExtract -- HKDF-Extract of RFC 5869 with SHA-256
+func hkdfExtract(salt []byte, ikm []byte) []byte {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write(ikm)
+	return mac.Sum(nil)
+}
+
+// hkdfExpand -- HKDF-Expand of RFC 5869 with SHA-256
+// l must be at most 255 * 32
+func hkdfExpand(prk []byte, info []byte, l int) []byte {
+	okm := make([]byte, 0, l+sha256.Size)
+	var t []byte
+	mac := hmac.New(sha256.New, prk)
+	for i := byte(1); len(okm) < l; i++ {
+		mac.Reset()
+		mac.Write(t)
+		mac.Write(info)
+		mac.Write([]byte{i})
+		t = mac.Sum(nil)
+		okm = append(okm, t...)
+	}
+	return okm[:l]
+}
+
+// hkdfModR -- HKDF_mod_r of EIP-2333, which is KeyGen of the IETF BLS signature draft
+// the output is never zero
+func hkdfModR(ikm []byte, keyInfo []byte) (sec SecretKey) {
+	const l = 48 // ceil((3 * ceil(log2(r))) / 16)
+	salt := []byte(keyGenSalt)
+	ikm0 := append(append([]byte{}, ikm...), 0)
+	info := make([]byte, len(keyInfo)+2)
+	copy(info, keyInfo)
+	binary.BigEndian.PutUint16(info[len(keyInfo):], l)
+	for sec.v.IsZero() {
+		h := sha256.Sum256(salt)
+		salt = h[:]
+		okm := hkdfExpand(hkdfExtract(salt, ikm0), info, l)
+		if err := sec.v.SetLittleEndianMod(reverseBytes(okm)); err != nil {
+			panic(err)
+		}
+	}
+	return sec
+}
+
+// reverseBytes returns a reversed copy of buf, converting between big and little endian
+func reverseBytes(buf []byte) []byte {
+	n := len(buf)
+	out := make([]byte, n)
+	for i := 0; i < n; i++ {
+		out[i] = buf[n-1-i]
+	}
+	return out
+}
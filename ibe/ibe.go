@@ -0,0 +1,259 @@
+// Package ibe implements Boneh-Franklin identity based encryption with the bls pairing.
+//
+// The private key generator holds a master SecretKey s whose PublicKey is sQ. The private key
+// of an identity is H(id)^s, which is exactly the bls signature of the master key on id, so
+// anyone can check an extracted key with Sign.Verify. A sender encrypts to an identity with
+// only the master PublicKey:
+//
+//	U = rQ, k = e(H(id), sQ)^r = e(H(id)^s, U)
+//
+// Two ciphertext versions are supported, both decrypted by Decrypt:
+//
+//	1 (Encrypt):    0x01 | U | AES-256-GCM(HKDF(k, U), plaintext)
+//	2 (EncryptCCA): 0x02 | U | V | AES-256-GCM(H4(sigma), plaintext)
+//
+// Version 2 is the Fujisaki-Okamoto transform (FullIdent): sigma is a random 32 byte string,
+// r = H3(sigma, H(plaintext)), V = sigma xor H2(k), and the decryptor recomputes r and checks
+// U = rQ, so a modified ciphertext is always rejected.
+//
+// The master key must be dedicated to identity key extraction: every signature it makes is
+// the private key of the signed identity.
+package ibe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spacemeshos/go-bls"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Ciphertext versions
+const (
+	VersionKEM byte = 1
+	VersionCCA byte = 2
+)
+
+// domain separation tags of the hash functions
+const (
+	kemInfo = "BLS-IBE-KEM-V1"
+	h2Tag   = "BLS-IBE-H2-V1"
+	h3Tag   = "BLS-IBE-H3-V1"
+	h4Tag   = "BLS-IBE-H4-V1"
+)
+
+// sigmaSize -- size of the random string of the CCA version
+const sigmaSize = 32
+
+// ErrDecrypt is returned when a ciphertext cannot be decrypted with the given key
+var ErrDecrypt = errors.New("err ibe:decryption failed")
+
+// Extract returns the private key of id, the signature of the master key on id
+func Extract(master *bls.SecretKey, id []byte) (*bls.Sign, error) {
+	if len(id) == 0 {
+		return nil, fmt.Errorf("err ibe:empty identity")
+	}
+	return master.Sign(id), nil
+}
+
+// VerifyKey checks the private key of id against the master public key
+// It is false for an empty id, or a zero or invalid key or master public key
+func VerifyKey(masterPub *bls.PublicKey, id []byte, key *bls.Sign) bool {
+	if len(id) == 0 || !masterPub.IsValid() || !key.IsValid() {
+		return false
+	}
+	return key.Verify(masterPub, id)
+}
+
+// Encrypt encrypts plaintext to id with the hybrid KEM (version 1)
+func Encrypt(masterPub *bls.PublicKey, id []byte, plaintext []byte) ([]byte, error) {
+	var r bls.Fr
+	for r.IsZero() {
+		r.SetByCSPRNG()
+	}
+	u, k, err := encapsulate(masterPub, id, &r)
+	if err != nil {
+		return nil, err
+	}
+	header := append([]byte{VersionKEM}, u.Serialize()...)
+	aead, err := newAEAD(kemKey(k, u))
+	if err != nil {
+		return nil, err
+	}
+	return seal(aead, header, plaintext), nil
+}
+
+// EncryptCCA encrypts plaintext to id with the Fujisaki-Okamoto transform (version 2)
+func EncryptCCA(masterPub *bls.PublicKey, id []byte, plaintext []byte) ([]byte, error) {
+	sigma := make([]byte, sigmaSize)
+	if err := bls.ReadRand(sigma); err != nil {
+		return nil, fmt.Errorf("err ibe:%v", err)
+	}
+	r, err := h3(sigma, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	u, k, err := encapsulate(masterPub, id, r)
+	if err != nil {
+		return nil, err
+	}
+	header := append([]byte{VersionCCA}, u.Serialize()...)
+	header = append(header, xorBytes(sigma, h2(k))...)
+	aead, err := newAEAD(h4(sigma))
+	if err != nil {
+		return nil, err
+	}
+	return seal(aead, header, plaintext), nil
+}
+
+// Decrypt decrypts a ciphertext of either version with the private key of its identity
+func Decrypt(key *bls.Sign, ciphertext []byte) ([]byte, error) {
+	q := bls.GetGeneratorOfG2()
+	g2Size := len(q.Serialize())
+	if len(ciphertext) < 1+g2Size {
+		return nil, ErrDecrypt
+	}
+	version := ciphertext[0]
+	var u bls.G2
+	if err := u.Deserialize(ciphertext[1 : 1+g2Size]); err != nil {
+		return nil, ErrDecrypt
+	}
+	if u.IsZero() || !u.IsValidOrder() {
+		return nil, ErrDecrypt
+	}
+	var k bls.GT
+	bls.Pairing(&k, bls.CastFromSign(key), &u)
+
+	switch version {
+	case VersionKEM:
+		header := ciphertext[:1+g2Size]
+		aead, err := newAEAD(kemKey(&k, &u))
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := aead.Open(nil, zeroNonce(aead), ciphertext[len(header):], header)
+		if err != nil {
+			return nil, ErrDecrypt
+		}
+		return plaintext, nil
+	case VersionCCA:
+		if len(ciphertext) < 1+g2Size+sigmaSize {
+			return nil, ErrDecrypt
+		}
+		header := ciphertext[:1+g2Size+sigmaSize]
+		sigma := xorBytes(header[1+g2Size:], h2(&k))
+		aead, err := newAEAD(h4(sigma))
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := aead.Open(nil, zeroNonce(aead), ciphertext[len(header):], header)
+		if err != nil {
+			return nil, ErrDecrypt
+		}
+		r, err := h3(sigma, plaintext)
+		if err != nil {
+			return nil, ErrDecrypt
+		}
+		var rq bls.G2
+		bls.G2Mul(&rq, &q, r)
+		if !rq.IsEqual(&u) {
+			return nil, ErrDecrypt
+		}
+		return plaintext, nil
+	default:
+		return nil, fmt.Errorf("err ibe:unknown ciphertext version %d", version)
+	}
+}
+
+// encapsulate returns U = rQ and k = e(H(id), masterPub)^r
+func encapsulate(masterPub *bls.PublicKey, id []byte, r *bls.Fr) (*bls.G2, *bls.GT, error) {
+	if len(id) == 0 {
+		return nil, nil, fmt.Errorf("err ibe:empty identity")
+	}
+	var h bls.G1
+	if err := h.HashAndMapTo(id); err != nil {
+		return nil, nil, err
+	}
+	q := bls.GetGeneratorOfG2()
+	u := new(bls.G2)
+	bls.G2Mul(u, &q, r)
+	var e bls.GT
+	bls.Pairing(&e, &h, bls.CastFromPublicKey(masterPub))
+	k := new(bls.GT)
+	bls.GTPow(k, &e, r)
+	return u, k, nil
+}
+
+// kemKey -- HKDF-SHA256(k, info = kemInfo | U) of the KEM version
+func kemKey(k *bls.GT, u *bls.G2) []byte {
+	info := append([]byte(kemInfo), u.Serialize()...)
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, k.Serialize(), nil, info), key); err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// h2 -- SHA256(h2Tag | k)
+func h2(k *bls.GT) []byte {
+	h := sha256.New()
+	h.Write([]byte(h2Tag))
+	h.Write(k.Serialize())
+	return h.Sum(nil)
+}
+
+// h3 -- r derived from sigma and the hash of the plaintext
+func h3(sigma []byte, plaintext []byte) (*bls.Fr, error) {
+	m := sha256.Sum256(plaintext)
+	buf := append([]byte(h3Tag), sigma...)
+	buf = append(buf, m[:]...)
+	r := new(bls.Fr)
+	if !r.SetHashOf(buf) || r.IsZero() {
+		return nil, fmt.Errorf("err ibe:bad H3 output")
+	}
+	return r, nil
+}
+
+// h4 -- SHA256(h4Tag | sigma), the AEAD key of the CCA version
+func h4(sigma []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(h4Tag))
+	h.Write(sigma)
+	return h.Sum(nil)
+}
+
+// newAEAD returns AES-256-GCM; every key is used for a single message so a zero nonce is safe
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("err ibe:%v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("err ibe:%v", err)
+	}
+	return aead, nil
+}
+
+// seal returns header | AEAD(plaintext) authenticating header
+func seal(aead cipher.AEAD, header []byte, plaintext []byte) []byte {
+	out := make([]byte, len(header), len(header)+len(plaintext)+aead.Overhead())
+	copy(out, header)
+	return aead.Seal(out, zeroNonce(aead), plaintext, header)
+}
+
+func zeroNonce(aead cipher.AEAD) []byte {
+	return make([]byte, aead.NonceSize())
+}
+
+func xorBytes(a []byte, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
@@ -0,0 +1,167 @@
+package bls12381
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+// ---------------- ZCash Encodings --------------------
+// Points use the compressed ZCash format of Ethereum: the big-endian x coordinate (c1 then c0 in G2)
+// with the flags compressed (0x80), infinity (0x40) and sign (0x20, y is the larger root) in the first
+// byte. The codecs go through the affine coordinates of mcl instead of SetETHserialization, which is
+// global, so they do not depend on the serialization mode of the process.
+
+// G1Size -- size of a compressed G1 point
+const G1Size = 48
+
+// G2Size -- size of a compressed G2 point
+const G2Size = 96
+
+const (
+	flagCompressed = 0x80
+	flagInfinity   = 0x40
+	flagSign       = 0x20
+)
+
+// DecodeG1 sets p from its compressed encoding, checking that it is in the subgroup
+func DecodeG1(buf []byte, p *bls.G1) error {
+	if len(buf) != G1Size {
+		return fmt.Errorf("err bls12381:bad G1 size %d", len(buf))
+	}
+	x, sign, infinity, err := decodeX(buf)
+	if err != nil {
+		return err
+	}
+	if infinity {
+		p.Clear()
+		return nil
+	}
+	y2 := fpAdd(fpMul(fpMul(x, x), x), big.NewInt(4))
+	y := new(big.Int).Exp(y2, sqrtExp, P)
+	if fpMul(y, y).Cmp(y2) != 0 {
+		return fmt.Errorf("err bls12381:G1 point not on the curve")
+	}
+	if (y.Cmp(halfP) > 0) != sign {
+		y.Sub(P, y)
+	}
+	return SetG1Affine(p, x, y)
+}
+
+// EncodeG1 returns the compressed encoding of p
+func EncodeG1(p *bls.G1) (out [G1Size]byte) {
+	v := affine(p.GetString(10))
+	if v == nil {
+		out[0] = flagCompressed | flagInfinity
+		return out
+	}
+	v[0].FillBytes(out[:])
+	out[0] |= flagCompressed
+	if v[1].Cmp(halfP) > 0 {
+		out[0] |= flagSign
+	}
+	return out
+}
+
+// DecodeG2 sets p from its compressed encoding, checking that it is in the subgroup
+func DecodeG2(buf []byte, p *bls.G2) error {
+	if len(buf) != G2Size {
+		return fmt.Errorf("err bls12381:bad G2 size %d", len(buf))
+	}
+	x1, sign, infinity, err := decodeX(buf[:G1Size])
+	if err != nil {
+		return err
+	}
+	x0 := new(big.Int).SetBytes(buf[G1Size:])
+	if x0.Cmp(P) >= 0 {
+		return fmt.Errorf("err bls12381:coordinate out of the field")
+	}
+	if infinity {
+		if x0.Sign() != 0 {
+			return fmt.Errorf("err bls12381:bad encoding of infinity")
+		}
+		p.Clear()
+		return nil
+	}
+	x := Fp2{x0, x1}
+	y, ok := x.Square().Mul(x).Add(NewFp2(4, 4)).Sqrt()
+	if !ok {
+		return fmt.Errorf("err bls12381:G2 point not on the curve")
+	}
+	if y.IsLarger() != sign {
+		y = y.Neg()
+	}
+	return SetG2Affine(p, x, y)
+}
+
+// EncodeG2 returns the compressed encoding of p
+func EncodeG2(p *bls.G2) (out [G2Size]byte) {
+	v := affine(p.GetString(10))
+	if v == nil {
+		out[0] = flagCompressed | flagInfinity
+		return out
+	}
+	v[1].FillBytes(out[:G1Size])
+	v[0].FillBytes(out[G1Size:])
+	out[0] |= flagCompressed
+	if (Fp2{v[2], v[3]}).IsLarger() {
+		out[0] |= flagSign
+	}
+	return out
+}
+
+// SetG1Affine sets p to (x, y), which must be a point of the subgroup
+func SetG1Affine(p *bls.G1, x, y *big.Int) error {
+	var q bls.G1
+	if err := q.SetString("1 "+x.String()+" "+y.String(), 10); err != nil || !q.IsValidOrder() {
+		return fmt.Errorf("err bls12381:G1 point not in the subgroup")
+	}
+	*p = q
+	return nil
+}
+
+// SetG2Affine sets p to (x, y), which must be a point of the subgroup
+func SetG2Affine(p *bls.G2, x, y Fp2) error {
+	var q bls.G2
+	s := strings.Join([]string{"1", x.C0.String(), x.C1.String(), y.C0.String(), y.C1.String()}, " ")
+	if err := q.SetString(s, 10); err != nil || !q.IsValidOrder() {
+		return fmt.Errorf("err bls12381:G2 point not in the subgroup")
+	}
+	*p = q
+	return nil
+}
+
+// decodeX -- the x coordinate (c1 in G2) and flags of a compressed point
+func decodeX(buf []byte) (x *big.Int, sign bool, infinity bool, err error) {
+	flags := buf[0] & (flagCompressed | flagInfinity | flagSign)
+	if flags&flagCompressed == 0 {
+		return nil, false, false, fmt.Errorf("err bls12381:uncompressed point")
+	}
+	x = new(big.Int).SetBytes(buf)
+	x.SetBit(x, len(buf)*8-1, 0).SetBit(x, len(buf)*8-2, 0).SetBit(x, len(buf)*8-3, 0)
+	if flags&flagInfinity != 0 {
+		if flags&flagSign != 0 || x.Sign() != 0 {
+			return nil, false, false, fmt.Errorf("err bls12381:bad encoding of infinity")
+		}
+		return x, false, true, nil
+	}
+	if x.Cmp(P) >= 0 {
+		return nil, false, false, fmt.Errorf("err bls12381:coordinate out of the field")
+	}
+	return x, flags&flagSign != 0, false, nil
+}
+
+// affine -- the coordinates of a point from its decimal string "1 x y", nil for infinity "0"
+func affine(s string) []*big.Int {
+	fields := strings.Fields(s)
+	if len(fields) < 3 || fields[0] != "1" {
+		return nil
+	}
+	v := make([]*big.Int, len(fields)-1)
+	for i := range v {
+		v[i] = mustBig(fields[i+1])
+	}
+	return v
+}
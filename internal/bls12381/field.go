@@ -0,0 +1,155 @@
+// Package bls12381 implements the base field arithmetic of BLS12-381 with math/big and the
+// compressed ZCash point encodings on top of the mcl points of the bls package.
+// It is shared by the kzg and eth packages, which must not depend on SetETHserialization.
+package bls12381
+
+import (
+	"math/big"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+var (
+	// P -- order of the base field
+	P = mustBig(bls.GetFieldOrder())
+	// R -- order of the groups
+	R = mustBig(bls.GetCurveOrder())
+
+	halfP   = new(big.Int).Rsh(P, 1)                                  // (p - 1) / 2
+	sqrtExp = new(big.Int).Rsh(new(big.Int).Add(P, big.NewInt(1)), 2) // (p + 1) / 4
+	fp2Exp  = new(big.Int).Rsh(new(big.Int).Sub(P, big.NewInt(3)), 2) // (p - 3) / 4
+)
+
+func mustBig(s string) *big.Int {
+	x, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("err bls12381:bad order " + s)
+	}
+	return x
+}
+
+func fpAdd(x, y *big.Int) *big.Int {
+	z := new(big.Int).Add(x, y)
+	return z.Mod(z, P)
+}
+
+func fpMul(x, y *big.Int) *big.Int {
+	z := new(big.Int).Mul(x, y)
+	return z.Mod(z, P)
+}
+
+func fpNeg(x *big.Int) *big.Int {
+	if x.Sign() == 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Sub(P, x)
+}
+
+// Fp2 -- C0 + C1 * u with u^2 = -1, both reduced mod P
+type Fp2 struct {
+	C0, C1 *big.Int
+}
+
+// NewFp2 returns c0 + c1 * u
+func NewFp2(c0, c1 int64) Fp2 {
+	return Fp2{new(big.Int).Mod(big.NewInt(c0), P), new(big.Int).Mod(big.NewInt(c1), P)}
+}
+
+// Add -- x + y
+func (x Fp2) Add(y Fp2) Fp2 {
+	return Fp2{fpAdd(x.C0, y.C0), fpAdd(x.C1, y.C1)}
+}
+
+// Sub -- x - y
+func (x Fp2) Sub(y Fp2) Fp2 {
+	return x.Add(y.Neg())
+}
+
+// Neg -- -x
+func (x Fp2) Neg() Fp2 {
+	return Fp2{fpNeg(x.C0), fpNeg(x.C1)}
+}
+
+// Mul -- x * y
+func (x Fp2) Mul(y Fp2) Fp2 {
+	return Fp2{
+		fpAdd(fpMul(x.C0, y.C0), fpNeg(fpMul(x.C1, y.C1))),
+		fpAdd(fpMul(x.C0, y.C1), fpMul(x.C1, y.C0)),
+	}
+}
+
+// Square -- x^2
+func (x Fp2) Square() Fp2 {
+	return x.Mul(x)
+}
+
+// Exp -- x^e
+func (x Fp2) Exp(e *big.Int) Fp2 {
+	z := NewFp2(1, 0)
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		z = z.Square()
+		if e.Bit(i) == 1 {
+			z = z.Mul(x)
+		}
+	}
+	return z
+}
+
+// Inverse -- 1 / x, 0 for x = 0
+func (x Fp2) Inverse() Fp2 {
+	norm := fpAdd(fpMul(x.C0, x.C0), fpMul(x.C1, x.C1))
+	if norm.Sign() == 0 {
+		return NewFp2(0, 0)
+	}
+	inv := new(big.Int).ModInverse(norm, P)
+	return Fp2{fpMul(x.C0, inv), fpMul(fpNeg(x.C1), inv)}
+}
+
+// IsZero --
+func (x Fp2) IsZero() bool {
+	return x.C0.Sign() == 0 && x.C1.Sign() == 0
+}
+
+// Equal --
+func (x Fp2) Equal(y Fp2) bool {
+	return x.C0.Cmp(y.C0) == 0 && x.C1.Cmp(y.C1) == 0
+}
+
+// IsLarger -- the sign of the compressed encoding, C1 > (p - 1) / 2 or C1 = 0 and C0 > (p - 1) / 2
+func (x Fp2) IsLarger() bool {
+	if x.C1.Sign() != 0 {
+		return x.C1.Cmp(halfP) > 0
+	}
+	return x.C0.Cmp(halfP) > 0
+}
+
+// Sgn0 -- the sign of RFC 9380, the parity of C0, or of C1 if C0 = 0
+func (x Fp2) Sgn0() int {
+	if x.C0.Sign() != 0 {
+		return int(x.C0.Bit(0))
+	}
+	return int(x.C1.Bit(0))
+}
+
+// Sqrt -- algorithm 9 of "Square root computation over even extension fields" for p = 3 mod 4
+// ok is false if x is not a square
+func (x Fp2) Sqrt() (y Fp2, ok bool) {
+	minusOne := NewFp2(-1, 0)
+	a1 := x.Exp(fp2Exp)
+	alpha := a1.Square().Mul(x)
+	conj := Fp2{alpha.C0, fpNeg(alpha.C1)}
+	if conj.Mul(alpha).Equal(minusOne) {
+		return Fp2{}, false
+	}
+	x0 := a1.Mul(x)
+	if alpha.Equal(minusOne) {
+		y = Fp2{fpNeg(x0.C1), x0.C0}
+	} else {
+		b := alpha.Add(NewFp2(1, 0)).Exp(halfP)
+		y = b.Mul(x0)
+	}
+	if !y.Square().Equal(x) {
+		return Fp2{}, false
+	}
+	return y, true
+}
@@ -0,0 +1,16 @@
+package bls
+
+import "fmt"
+
+// KeyGenMinIKMSize -- minimum size of the input keying material accepted by KeyGen
+const KeyGenMinIKMSize = 32
+
+// KeyGen deterministically derives a secret key from input keying material and optional key info
+// as specified by KeyGen of the IETF BLS signature draft (draft-irtf-cfrg-bls-signature-04)
+// ikm must be at least 32 bytes and should be uniformly random
+func KeyGen(ikm []byte, keyInfo []byte) (SecretKey, error) {
+	if len(ikm) < KeyGenMinIKMSize {
+		return SecretKey{}, fmt.Errorf("err KeyGen:ikm must be at least %d bytes, got %d", KeyGenMinIKMSize, len(ikm))
+	}
+	return hkdfModR(ikm, keyInfo), nil
+}
@@ -0,0 +1,362 @@
+// Package keystore encrypts and decrypts bls secret keys in the EIP-2335 keystore format
+// See https://eips.ethereum.org/EIPS/eip-2335
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/spacemeshos/go-bls"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Version -- the keystore version defined by EIP-2335
+const Version = 4
+
+// Supported kdf, checksum and cipher functions
+const (
+	KDFScrypt      = "scrypt"
+	KDFPBKDF2      = "pbkdf2"
+	ChecksumSHA256 = "sha256"
+	CipherAES128   = "aes-128-ctr"
+)
+
+// kdf parameters and sizes recommended by EIP-2335
+const (
+	scryptN       = 262144
+	scryptR       = 8
+	scryptP       = 1
+	pbkdf2C       = 262144
+	pbkdf2PRF     = "hmac-sha256"
+	dkLen         = 32
+	secretSize    = 32
+	saltSize      = 32
+	ivSize        = aes.BlockSize
+	cipherKeySize = 16
+)
+
+// bounds of the KDF parameters of keystores to decrypt, at most 1 GiB of memory as with
+// the scrypt parameters of EIP-2335 and 64 times its PBKDF2 count
+const (
+	maxScryptN      = 1 << 20
+	maxScryptP      = 16
+	maxScryptMemory = 1 << 30 // 128 * n * r bytes
+	maxPBKDF2C      = 1 << 24
+)
+
+// ErrInvalidPassword is returned by Decrypt when the checksum does not match
+var ErrInvalidPassword = errors.New("err keystore:invalid password")
+
+// Keystore -- an EIP-2335 keystore
+type Keystore struct {
+	Crypto      Crypto `json:"crypto"`
+	Description string `json:"description"`
+	Pubkey      string `json:"pubkey"`
+	Path        string `json:"path"`
+	UUID        string `json:"uuid"`
+	Version     int    `json:"version"`
+}
+
+// Crypto -- the kdf, checksum and cipher modules of a keystore
+type Crypto struct {
+	KDF      Module `json:"kdf"`
+	Checksum Module `json:"checksum"`
+	Cipher   Module `json:"cipher"`
+}
+
+// Module -- a keystore module: a function, its parameters and its message
+type Module struct {
+	Function string          `json:"function"`
+	Params   json.RawMessage `json:"params"`
+	Message  string          `json:"message"`
+}
+
+type scryptParams struct {
+	DKLen int    `json:"dklen"`
+	N     int    `json:"n"`
+	P     int    `json:"p"`
+	R     int    `json:"r"`
+	Salt  string `json:"salt"`
+}
+
+type pbkdf2Params struct {
+	DKLen int    `json:"dklen"`
+	C     int    `json:"c"`
+	PRF   string `json:"prf"`
+	Salt  string `json:"salt"`
+}
+
+type cipherParams struct {
+	IV string `json:"iv"`
+}
+
+// Load parses a JSON encoded keystore
+func Load(data []byte) (*Keystore, error) {
+	ks := new(Keystore)
+	if err := json.Unmarshal(data, ks); err != nil {
+		return nil, fmt.Errorf("err keystore:%v", err)
+	}
+	if ks.Version != Version {
+		return nil, fmt.Errorf("err keystore:unsupported version %d", ks.Version)
+	}
+	return ks, nil
+}
+
+// Marshal returns the JSON encoding of the keystore
+func (ks *Keystore) Marshal() ([]byte, error) {
+	return json.MarshalIndent(ks, "", "  ")
+}
+
+// Encrypt encrypts sec with password using scrypt
+// path is the EIP-2334 derivation path of the key and may be empty
+func Encrypt(sec *bls.SecretKey, password string, path string) (*Keystore, error) {
+	return EncryptWithKDF(sec, password, path, KDFScrypt)
+}
+
+// EncryptWithKDF encrypts sec with password using the given kdf function (KDFScrypt or KDFPBKDF2)
+func EncryptWithKDF(sec *bls.SecretKey, password string, path string, kdf string) (*Keystore, error) {
+	if path != "" {
+		if err := bls.ValidatePath(path); err != nil {
+			return nil, err
+		}
+	}
+	salt, err := randomBytes(saltSize)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := randomBytes(ivSize)
+	if err != nil {
+		return nil, err
+	}
+	uuid, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	var params interface{}
+	switch kdf {
+	case KDFScrypt:
+		params = scryptParams{DKLen: dkLen, N: scryptN, P: scryptP, R: scryptR, Salt: hex.EncodeToString(salt)}
+	case KDFPBKDF2:
+		params = pbkdf2Params{DKLen: dkLen, C: pbkdf2C, PRF: pbkdf2PRF, Salt: hex.EncodeToString(salt)}
+	default:
+		return nil, fmt.Errorf("err keystore:unsupported kdf %q", kdf)
+	}
+	ks := &Keystore{
+		Pubkey:  hex.EncodeToString(sec.GetPublicKey().Serialize()),
+		Path:    path,
+		UUID:    uuid,
+		Version: Version,
+	}
+	ks.Crypto.KDF = Module{Function: kdf, Params: mustMarshal(params)}
+	ks.Crypto.Cipher = Module{Function: CipherAES128, Params: mustMarshal(cipherParams{IV: hex.EncodeToString(iv)})}
+	ks.Crypto.Checksum = Module{Function: ChecksumSHA256, Params: json.RawMessage("{}")}
+
+	dk, err := ks.decryptionKey(password)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := aes128CTR(dk[:cipherKeySize], iv, secretKeyToBytes(sec))
+	if err != nil {
+		return nil, err
+	}
+	ks.Crypto.Cipher.Message = hex.EncodeToString(ciphertext)
+	ks.Crypto.Checksum.Message = hex.EncodeToString(checksum(dk, ciphertext))
+	return ks, nil
+}
+
+// Decrypt decrypts the secret key with password
+// Returns ErrInvalidPassword if the checksum does not match
+// The pubkey field is informational and is not checked, as keystores from other
+// implementations may hold a public key in a different group or encoding
+func (ks *Keystore) Decrypt(password string) (bls.SecretKey, error) {
+	var sec bls.SecretKey
+	if ks.Crypto.Checksum.Function != ChecksumSHA256 {
+		return sec, fmt.Errorf("err keystore:unsupported checksum %q", ks.Crypto.Checksum.Function)
+	}
+	if ks.Crypto.Cipher.Function != CipherAES128 {
+		return sec, fmt.Errorf("err keystore:unsupported cipher %q", ks.Crypto.Cipher.Function)
+	}
+	var cp cipherParams
+	if err := json.Unmarshal(ks.Crypto.Cipher.Params, &cp); err != nil {
+		return sec, fmt.Errorf("err keystore:bad cipher params:%v", err)
+	}
+	iv, err := hex.DecodeString(cp.IV)
+	if err != nil || len(iv) != ivSize {
+		return sec, fmt.Errorf("err keystore:bad cipher iv %q", cp.IV)
+	}
+	ciphertext, err := hex.DecodeString(ks.Crypto.Cipher.Message)
+	if err != nil || len(ciphertext) != secretSize {
+		return sec, fmt.Errorf("err keystore:bad cipher message")
+	}
+	sum, err := hex.DecodeString(ks.Crypto.Checksum.Message)
+	if err != nil {
+		return sec, fmt.Errorf("err keystore:bad checksum message")
+	}
+
+	dk, err := ks.decryptionKey(password)
+	if err != nil {
+		return sec, err
+	}
+	if subtle.ConstantTimeCompare(checksum(dk, ciphertext), sum) != 1 {
+		return sec, ErrInvalidPassword
+	}
+	secret, err := aes128CTR(dk[:cipherKeySize], iv, ciphertext)
+	if err != nil {
+		return sec, err
+	}
+	if err := secretKeyFromBytes(&sec, secret); err != nil {
+		return sec, err
+	}
+	return sec, nil
+}
+
+// decryptionKey runs the kdf module over the processed password
+func (ks *Keystore) decryptionKey(password string) ([]byte, error) {
+	pw := processPassword(password)
+	switch ks.Crypto.KDF.Function {
+	case KDFScrypt:
+		var p scryptParams
+		if err := json.Unmarshal(ks.Crypto.KDF.Params, &p); err != nil {
+			return nil, fmt.Errorf("err keystore:bad scrypt params:%v", err)
+		}
+		salt, err := decodeSalt(p.Salt, p.DKLen)
+		if err != nil {
+			return nil, err
+		}
+		if p.N <= 1 || p.N > maxScryptN || p.R <= 0 || p.P <= 0 || p.P > maxScryptP || p.R > maxScryptMemory/(128*p.N) {
+			return nil, fmt.Errorf("err keystore:scrypt params n=%d r=%d p=%d out of bounds", p.N, p.R, p.P)
+		}
+		dk, err := scrypt.Key(pw, salt, p.N, p.R, p.P, p.DKLen)
+		if err != nil {
+			return nil, fmt.Errorf("err keystore:scrypt:%v", err)
+		}
+		return dk, nil
+	case KDFPBKDF2:
+		var p pbkdf2Params
+		if err := json.Unmarshal(ks.Crypto.KDF.Params, &p); err != nil {
+			return nil, fmt.Errorf("err keystore:bad pbkdf2 params:%v", err)
+		}
+		if p.PRF != pbkdf2PRF {
+			return nil, fmt.Errorf("err keystore:unsupported pbkdf2 prf %q", p.PRF)
+		}
+		if p.C <= 0 || p.C > maxPBKDF2C {
+			return nil, fmt.Errorf("err keystore:bad pbkdf2 count %d", p.C)
+		}
+		salt, err := decodeSalt(p.Salt, p.DKLen)
+		if err != nil {
+			return nil, err
+		}
+		return pbkdf2.Key(pw, salt, p.C, p.DKLen, sha256.New), nil
+	default:
+		return nil, fmt.Errorf("err keystore:unsupported kdf %q", ks.Crypto.KDF.Function)
+	}
+}
+
+// decodeSalt decodes a hex salt and checks that dklen is large enough for the checksum and cipher key
+func decodeSalt(s string, dklen int) ([]byte, error) {
+	if dklen < dkLen {
+		return nil, fmt.Errorf("err keystore:dklen must be at least %d, got %d", dkLen, dklen)
+	}
+	salt, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("err keystore:bad salt %q", s)
+	}
+	return salt, nil
+}
+
+// processPassword applies NFKD normalization and strips control codes as required by EIP-2335
+func processPassword(password string) []byte {
+	out := make([]rune, 0, len(password))
+	for _, r := range norm.NFKD.String(password) {
+		if r < 0x20 || (r >= 0x7f && r <= 0x9f) {
+			continue
+		}
+		out = append(out, r)
+	}
+	return []byte(string(out))
+}
+
+// checksum -- SHA256(dk[16:32] | ciphertext)
+func checksum(dk []byte, ciphertext []byte) []byte {
+	h := sha256.New()
+	h.Write(dk[16:32])
+	h.Write(ciphertext)
+	return h.Sum(nil)
+}
+
+// aes128CTR encrypts or decrypts in with AES-128-CTR
+func aes128CTR(key []byte, iv []byte, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("err keystore:%v", err)
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
+
+// secretKeyToBytes returns the 32 byte big-endian encoding of sec
+func secretKeyToBytes(sec *bls.SecretKey) []byte {
+	le := sec.GetLittleEndian()
+	out := make([]byte, secretSize)
+	for i := 0; i < len(le) && i < secretSize; i++ {
+		out[secretSize-1-i] = le[i]
+	}
+	return out
+}
+
+// secretKeyFromBytes sets sec from a 32 byte big-endian encoding, rejecting zero and values not less than the curve order
+func secretKeyFromBytes(sec *bls.SecretKey, buf []byte) error {
+	le := make([]byte, len(buf))
+	for i := range buf {
+		le[len(buf)-1-i] = buf[i]
+	}
+	if err := sec.SetLittleEndian(le); err != nil {
+		return fmt.Errorf("err keystore:%v", err)
+	}
+	// SetLittleEndian masks values that are too large, so a different encoding means an invalid secret
+	if subtle.ConstantTimeCompare(secretKeyToBytes(sec), buf) != 1 {
+		return fmt.Errorf("err keystore:secret is not less than the curve order")
+	}
+	var zero bls.SecretKey
+	if sec.IsEqual(&zero) {
+		return fmt.Errorf("err keystore:secret is zero")
+	}
+	return nil
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() (string, error) {
+	b, err := randomBytes(16)
+	if err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if err := bls.ReadRand(b); err != nil {
+		return nil, fmt.Errorf("err keystore:%v", err)
+	}
+	return b, nil
+}
+
+func mustMarshal(v interface{}) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,182 @@
+package kzg
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+// ---------------- EIP-4844 --------------------
+// The blob functions of the polynomial commitments of EIP-4844 over encoded values. A blob is the
+// concatenation of the big-endian evaluations of a polynomial, so it holds 32 * Size() bytes: with
+// the setup of EIP-4844 (4096 G1 and 65 G2 points) the functions follow the specification.
+
+// FieldElementsPerBlob -- the size of the setup of EIP-4844
+const FieldElementsPerBlob = 4096
+
+// BytesPerBlob -- the size of a blob of EIP-4844
+const BytesPerBlob = FieldElementsPerBlob * FrSize
+
+const (
+	fiatShamirDomain     = "FSBLOBVERIFY_V1_"
+	batchChallengeDomain = "RCKZGBATCH___V1_"
+)
+
+// Commitment -- a compressed commitment to a blob
+type Commitment [G1Size]byte
+
+// Proof -- a compressed opening proof
+type Proof [G1Size]byte
+
+// BlobToPolynomial decodes the evaluations of blob
+func (s *Setup) BlobToPolynomial(blob []byte) (Polynomial, error) {
+	if len(blob) != s.n*FrSize {
+		return nil, fmt.Errorf("err kzg:blob of %d bytes for a setup of %d", len(blob), s.n)
+	}
+	p := make(Polynomial, s.n)
+	for i := range p {
+		if err := DecodeFr(blob[i*FrSize:(i+1)*FrSize], &p[i]); err != nil {
+			return nil, fmt.Errorf("%v at element %d", err, i)
+		}
+	}
+	return p, nil
+}
+
+// BlobToCommitment -- blob_to_kzg_commitment
+func (s *Setup) BlobToCommitment(blob []byte) (Commitment, error) {
+	p, err := s.BlobToPolynomial(blob)
+	if err != nil {
+		return Commitment{}, err
+	}
+	c, err := s.Commit(p)
+	if err != nil {
+		return Commitment{}, err
+	}
+	return EncodeG1(c), nil
+}
+
+// ComputeProof -- compute_kzg_proof, the proof of the evaluation of blob at z and the evaluation
+func (s *Setup) ComputeProof(blob []byte, z [FrSize]byte) (Proof, [FrSize]byte, error) {
+	p, err := s.BlobToPolynomial(blob)
+	if err != nil {
+		return Proof{}, [FrSize]byte{}, err
+	}
+	var zFr bls.Fr
+	if err := DecodeFr(z[:], &zFr); err != nil {
+		return Proof{}, [FrSize]byte{}, err
+	}
+	proof, y, err := s.Open(p, &zFr)
+	if err != nil {
+		return Proof{}, [FrSize]byte{}, err
+	}
+	return EncodeG1(proof), EncodeFr(y), nil
+}
+
+// VerifyProof -- verify_kzg_proof, false if a value is not canonical
+func (s *Setup) VerifyProof(commitment Commitment, z [FrSize]byte, y [FrSize]byte, proof Proof) bool {
+	var c, pi bls.G1
+	var zFr, yFr bls.Fr
+	if DecodeG1(commitment[:], &c) != nil || DecodeG1(proof[:], &pi) != nil {
+		return false
+	}
+	if DecodeFr(z[:], &zFr) != nil || DecodeFr(y[:], &yFr) != nil {
+		return false
+	}
+	return s.Verify(&c, &zFr, &yFr, &pi)
+}
+
+// ComputeBlobProof -- compute_blob_kzg_proof, the proof of the evaluation of blob at its challenge
+func (s *Setup) ComputeBlobProof(blob []byte, commitment Commitment) (Proof, error) {
+	var c bls.G1
+	if err := DecodeG1(commitment[:], &c); err != nil {
+		return Proof{}, err
+	}
+	p, err := s.BlobToPolynomial(blob)
+	if err != nil {
+		return Proof{}, err
+	}
+	z := s.challenge(blob, commitment)
+	proof, _, err := s.Open(p, &z)
+	if err != nil {
+		return Proof{}, err
+	}
+	return EncodeG1(proof), nil
+}
+
+// VerifyBlobProof -- verify_blob_kzg_proof, false if a value is not canonical
+func (s *Setup) VerifyBlobProof(blob []byte, commitment Commitment, proof Proof) bool {
+	c, z, y, pi, err := s.blobOpening(blob, commitment, proof)
+	if err != nil {
+		return false
+	}
+	return s.Verify(&c, &z, &y, &pi)
+}
+
+// VerifyBlobProofBatch -- verify_blob_kzg_proof_batch, true for no blobs as in the specification
+func (s *Setup) VerifyBlobProofBatch(blobs [][]byte, commitments []Commitment, proofs []Proof) bool {
+	n := len(blobs)
+	if len(commitments) != n || len(proofs) != n {
+		return false
+	}
+	if n == 0 {
+		return true
+	}
+	cVec := make([]bls.G1, n)
+	zVec := make([]bls.Fr, n)
+	yVec := make([]bls.Fr, n)
+	piVec := make([]bls.G1, n)
+	// r = hash(domain | n_fe | n | commitment_i | z_i | y_i | proof_i)
+	data := make([]byte, 0, len(batchChallengeDomain)+16+n*(2*G1Size+2*FrSize))
+	data = append(data, batchChallengeDomain...)
+	data = binary.BigEndian.AppendUint64(data, uint64(s.n))
+	data = binary.BigEndian.AppendUint64(data, uint64(n))
+	for i := range blobs {
+		var err error
+		cVec[i], zVec[i], yVec[i], piVec[i], err = s.blobOpening(blobs[i], commitments[i], proofs[i])
+		if err != nil {
+			return false
+		}
+		z, y := EncodeFr(&zVec[i]), EncodeFr(&yVec[i])
+		data = append(data, commitments[i][:]...)
+		data = append(data, z[:]...)
+		data = append(data, y[:]...)
+		data = append(data, proofs[i][:]...)
+	}
+	if n == 1 {
+		return s.Verify(&cVec[0], &zVec[0], &yVec[0], &piVec[0])
+	}
+	r := hashToFr(data)
+	return s.verifyBatch(cVec, zVec, yVec, piVec, &r)
+}
+
+// blobOpening -- the decoded commitment and proof of blob, its challenge and evaluation
+func (s *Setup) blobOpening(blob []byte, commitment Commitment, proof Proof) (c bls.G1, z bls.Fr, y bls.Fr, pi bls.G1, err error) {
+	if err = DecodeG1(commitment[:], &c); err != nil {
+		return
+	}
+	if err = DecodeG1(proof[:], &pi); err != nil {
+		return
+	}
+	p, err := s.BlobToPolynomial(blob)
+	if err != nil {
+		return
+	}
+	z = s.challenge(blob, commitment)
+	v, err := s.Evaluate(p, &z)
+	if err != nil {
+		return
+	}
+	return c, z, *v, pi, nil
+}
+
+// challenge -- compute_challenge, hash(domain | n as 16 bytes | blob | commitment)
+func (s *Setup) challenge(blob []byte, commitment Commitment) bls.Fr {
+	data := make([]byte, 0, len(fiatShamirDomain)+16+len(blob)+G1Size)
+	data = append(data, fiatShamirDomain...)
+	data = binary.BigEndian.AppendUint64(data, 0)
+	data = binary.BigEndian.AppendUint64(data, uint64(s.n))
+	data = append(data, blob...)
+	data = append(data, commitment[:]...)
+	return hashToFr(data)
+}
@@ -0,0 +1,82 @@
+package kzg
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/internal/bls12381"
+)
+
+// ---------------- EIP-4844 Encodings --------------------
+// Points use the compressed ZCash format of Ethereum: the big-endian x coordinate (c1 then c0 in G2)
+// with the flags compressed (0x80), infinity (0x40) and sign (0x20, y is the larger root) in the first
+// byte, see the bls12381 package. Field elements are big-endian and must be below the order of the group.
+
+// G1Size -- size of a compressed G1 point
+const G1Size = bls12381.G1Size
+
+// G2Size -- size of a compressed G2 point
+const G2Size = bls12381.G2Size
+
+// FrSize -- size of a field element
+const FrSize = 32
+
+var curveOrder = bls12381.R
+
+// DecodeG1 sets p from its compressed encoding, checking that it is in the subgroup
+func DecodeG1(buf []byte, p *bls.G1) error {
+	return bls12381.DecodeG1(buf, p)
+}
+
+// EncodeG1 returns the compressed encoding of p
+func EncodeG1(p *bls.G1) [G1Size]byte {
+	return bls12381.EncodeG1(p)
+}
+
+// DecodeG2 sets p from its compressed encoding, checking that it is in the subgroup
+func DecodeG2(buf []byte, p *bls.G2) error {
+	return bls12381.DecodeG2(buf, p)
+}
+
+// EncodeG2 returns the compressed encoding of p
+func EncodeG2(p *bls.G2) [G2Size]byte {
+	return bls12381.EncodeG2(p)
+}
+
+// DecodeFr sets x from its big-endian encoding, which must be below the order of the group
+func DecodeFr(buf []byte, x *bls.Fr) error {
+	if len(buf) != FrSize {
+		return fmt.Errorf("err kzg:bad field element size %d", len(buf))
+	}
+	v := new(big.Int).SetBytes(buf)
+	if v.Cmp(curveOrder) >= 0 {
+		return fmt.Errorf("err kzg:field element out of range")
+	}
+	return setFr(x, v)
+}
+
+// EncodeFr returns the big-endian encoding of x
+func EncodeFr(x *bls.Fr) (out [FrSize]byte) {
+	v, ok := new(big.Int).SetString(x.GetString(10), 10)
+	if !ok {
+		panic("err kzg:bad field element " + x.GetString(10))
+	}
+	v.FillBytes(out[:])
+	return out
+}
+
+// hashToFr -- SHA-256(data) as a big-endian integer mod r, hash_to_bls_field of EIP-4844
+func hashToFr(data []byte) (x bls.Fr) {
+	h := sha256.Sum256(data)
+	v := new(big.Int).SetBytes(h[:])
+	if err := setFr(&x, v.Mod(v, curveOrder)); err != nil {
+		panic(err)
+	}
+	return x
+}
+
+func setFr(x *bls.Fr, v *big.Int) error {
+	return x.SetString(v.String(), 10)
+}
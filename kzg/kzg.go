@@ -0,0 +1,625 @@
+// Package kzg implements KZG polynomial commitments over BLS12-381 with the trusted setup and
+// conventions of EIP-4844.
+//
+// A polynomial of degree below n is given by its evaluations over the n-th roots of unity, in
+// bit-reversed order as in EIP-4844, and committed to as the multi-scalar multiplication of the
+// evaluations with the Lagrange basis of the setup, [p(tau)]G1. An opening at z is the commitment
+// to the quotient (p(X) - p(z)) / (X - z), verified with
+//
+//	e(C - [y]G1, G2) = e(proof, [tau]G2 - [z]G2)
+//
+// An opening at k points S proves p = I on S, where I interpolates p on S, with the commitment to
+// (p(X) - I(X)) / Z_S(X) and needs the first k + 1 powers of tau in G2 of the setup.
+//
+// The setup is used as is: its points are only checked for consistency when it is loaded.
+package kzg
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"math/bits"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+// primitiveRoot -- generator of the multiplicative group of Fr used by EIP-4844 for the roots of unity
+const primitiveRoot = 7
+
+// maxLogSize -- the 2-adicity of r - 1, the largest domain is 2^32
+const maxLogSize = 32
+
+// the standard generators of BLS12-381, compressed
+const (
+	g1Generator = "97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb"
+	g2Generator = "93e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e" +
+		"024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8"
+)
+
+// Setup -- a trusted setup: the Lagrange basis [L_i(tau)]G1 over the n-th roots of unity and the
+// powers of tau in G2
+type Setup struct {
+	n          int
+	g1         bls.G1   // generator
+	g1Lagrange []bls.G1 // bit-reversed order
+	g2         []bls.G2 // [tau^i]G2, g2[0] is the generator
+	roots      []bls.Fr // bit-reversed order
+	nInv       bls.Fr
+}
+
+// Polynomial -- the evaluations of a polynomial over the domain of a Setup, in bit-reversed order
+type Polynomial []bls.Fr
+
+// LoadTrustedSetupFile -- LoadTrustedSetup from the file at path
+func LoadTrustedSetupFile(path string) (*Setup, error) {
+	f, err := os.Open(path) // #nosec
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadTrustedSetup(f)
+}
+
+// LoadTrustedSetup reads a setup in the text format of the EIP-4844 trusted_setup.txt of c-kzg:
+// the number of G1 points n, the number of G2 points, the n Lagrange G1 points in natural order,
+// the G2 powers of tau and, in the newer files, the n monomial G1 points [tau^i]G1, in hex,
+// separated by white space.
+// The monomial points are not used: only the first two are checked against the Lagrange points.
+func LoadTrustedSetup(r io.Reader) (*Setup, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	next := func() (string, bool) {
+		if !scanner.Scan() {
+			return "", false
+		}
+		return scanner.Text(), true
+	}
+	counts := make([]int, 2)
+	for i := range counts {
+		s, ok := next()
+		if !ok {
+			return nil, fmt.Errorf("err kzg:truncated setup")
+		}
+		v, err := strconv.Atoi(s)
+		if err != nil || v <= 0 || v > 1<<maxLogSize {
+			return nil, fmt.Errorf("err kzg:bad number of points %q", s)
+		}
+		counts[i] = v
+	}
+	var tokens []string
+	for s, ok := next(); ok; s, ok = next() {
+		tokens = append(tokens, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	n, nG2 := counts[0], counts[1]
+	if len(tokens) != n+nG2 && len(tokens) != 2*n+nG2 {
+		return nil, fmt.Errorf("err kzg:%d points for %d G1 and %d G2 points", len(tokens), n, nG2)
+	}
+	lagrange, err := decodeG1Tokens(tokens[:n], "Lagrange G1")
+	if err != nil {
+		return nil, err
+	}
+	g2Vec := make([]bls.G2, nG2)
+	for i, s := range tokens[n : n+nG2] {
+		buf, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("err kzg:bad hex at G2 point %d", i)
+		}
+		if err := DecodeG2(buf, &g2Vec[i]); err != nil {
+			return nil, fmt.Errorf("%v at G2 point %d", err, i)
+		}
+	}
+	s, err := newSetup(lagrange, g2Vec)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == n+nG2 {
+		return s, nil
+	}
+	m := 2
+	if n < m {
+		m = n
+	}
+	monomial, err := decodeG1Tokens(tokens[n+nG2:n+nG2+m], "monomial G1")
+	if err != nil {
+		return nil, err
+	}
+	if !monomial[0].IsEqual(&s.g1) {
+		return nil, fmt.Errorf("err kzg:monomial G1 point 0 is not the generator")
+	}
+	if n > 1 {
+		var tau1 bls.G1
+		if err := bls.G1MulVec(&tau1, lagrange, rootsOfUnity(n)); err != nil {
+			return nil, err
+		}
+		if !monomial[1].IsEqual(&tau1) {
+			return nil, fmt.Errorf("err kzg:monomial G1 point 1 does not match the Lagrange points")
+		}
+	}
+	return s, nil
+}
+
+// decodeG1Tokens -- the G1 points of the hex tokens, what names them in errors
+func decodeG1Tokens(tokens []string, what string) ([]bls.G1, error) {
+	g1Vec := make([]bls.G1, len(tokens))
+	for i, s := range tokens {
+		buf, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("err kzg:bad hex at %s point %d", what, i)
+		}
+		if err := DecodeG1(buf, &g1Vec[i]); err != nil {
+			return nil, fmt.Errorf("%v at %s point %d", err, what, i)
+		}
+	}
+	return g1Vec, nil
+}
+
+// NewInsecureSetup returns the setup of a known tau with n G1 points and nG2 powers of tau in G2
+// For tests only: anyone knowing tau can open a commitment to any value
+func NewInsecureSetup(tau *bls.Fr, n int, nG2 int) (*Setup, error) {
+	if n <= 0 || n&(n-1) != 0 || n > 1<<maxLogSize || nG2 < 2 {
+		return nil, fmt.Errorf("err kzg:bad setup size %d, %d", n, nG2)
+	}
+	var g1 bls.G1
+	var g2 bls.G2
+	if err := DecodeG1(mustHex(g1Generator), &g1); err != nil {
+		return nil, err
+	}
+	if err := DecodeG2(mustHex(g2Generator), &g2); err != nil {
+		return nil, err
+	}
+	roots := rootsOfUnity(n)
+	// L_i(tau) = (tau^n - 1) / n * w_i / (tau - w_i)
+	var scale, nFr bls.Fr
+	frPow(&scale, tau, uint64(n))
+	var one bls.Fr
+	one.SetInt64(1)
+	bls.FrSub(&scale, &scale, &one)
+	nFr.SetInt64(int64(n))
+	bls.FrDiv(&scale, &scale, &nFr)
+	den := make([]bls.Fr, n)
+	for i := range roots {
+		bls.FrSub(&den[i], tau, &roots[i])
+		if den[i].IsZero() {
+			return nil, fmt.Errorf("err kzg:tau in the domain")
+		}
+	}
+	inv := make([]bls.Fr, n)
+	batchInv(inv, den)
+	lagrange := make([]bls.G1, n)
+	for i := range roots {
+		var c bls.Fr
+		bls.FrMul(&c, &roots[i], &inv[i])
+		bls.FrMul(&c, &c, &scale)
+		bls.G1Mul(&lagrange[i], &g1, &c)
+	}
+	g2Vec := make([]bls.G2, nG2)
+	g2Vec[0] = g2
+	for i := 1; i < nG2; i++ {
+		bls.G2Mul(&g2Vec[i], &g2Vec[i-1], tau)
+	}
+	return newSetup(lagrange, g2Vec)
+}
+
+// newSetup checks the consistency of a Lagrange basis in natural order with the powers of tau in G2
+func newSetup(lagrange []bls.G1, g2Vec []bls.G2) (*Setup, error) {
+	n := len(lagrange)
+	if n&(n-1) != 0 || len(g2Vec) < 2 {
+		return nil, fmt.Errorf("err kzg:bad setup size %d, %d", n, len(g2Vec))
+	}
+	s := &Setup{n: n, g2: g2Vec}
+	for i := range lagrange {
+		bls.G1Add(&s.g1, &s.g1, &lagrange[i])
+	}
+	if s.g1.IsZero() || g2Vec[0].IsZero() {
+		return nil, fmt.Errorf("err kzg:bad setup generators")
+	}
+	// sum_i w_i L_i(X) = X so e(sum_i w_i [L_i(tau)]G1, G2) = e(G1, [tau]G2)
+	roots := rootsOfUnity(n)
+	var tau1 bls.G1
+	if err := bls.G1MulVec(&tau1, lagrange, roots); err != nil {
+		return nil, err
+	}
+	var neg bls.G1
+	bls.G1Neg(&neg, &s.g1)
+	if !pairingCheck(&tau1, &g2Vec[0], &neg, &g2Vec[1]) {
+		return nil, fmt.Errorf("err kzg:inconsistent G1 and G2 points")
+	}
+	// e([tau]G1, [tau^(i-1)]G2) = e(G1, [tau^i]G2)
+	for i := 2; i < len(g2Vec); i++ {
+		if !pairingCheck(&tau1, &g2Vec[i-1], &neg, &g2Vec[i]) {
+			return nil, fmt.Errorf("err kzg:G2 point %d is not a power of tau", i)
+		}
+	}
+	s.g1Lagrange = make([]bls.G1, n)
+	s.roots = make([]bls.Fr, n)
+	for i := range lagrange {
+		j := reverseBits(i, n)
+		s.g1Lagrange[j] = lagrange[i]
+		s.roots[j] = roots[i]
+	}
+	var nFr bls.Fr
+	nFr.SetInt64(int64(n))
+	bls.FrInv(&s.nInv, &nFr)
+	return s, nil
+}
+
+// WriteTo writes s in the format read by LoadTrustedSetup, with the Lagrange points in natural order
+func (s *Setup) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	written := 0
+	write := func(str string) {
+		m, _ := bw.WriteString(str)
+		written += m
+	}
+	write(fmt.Sprintf("%d\n%d\n", s.n, len(s.g2)))
+	for i := 0; i < s.n; i++ {
+		p := EncodeG1(&s.g1Lagrange[reverseBits(i, s.n)])
+		write(hex.EncodeToString(p[:]) + "\n")
+	}
+	for i := range s.g2 {
+		p := EncodeG2(&s.g2[i])
+		write(hex.EncodeToString(p[:]) + "\n")
+	}
+	return int64(written), bw.Flush()
+}
+
+// Size -- the number of evaluations of the polynomials of s
+func (s *Setup) Size() int {
+	return s.n
+}
+
+// MaxOpeningPoints -- the largest number of points of OpenMulti
+func (s *Setup) MaxOpeningPoints() int {
+	return len(s.g2) - 1
+}
+
+// Roots returns the domain of s in bit-reversed order
+func (s *Setup) Roots() []bls.Fr {
+	return append([]bls.Fr{}, s.roots...)
+}
+
+// Commit returns the commitment [p(tau)]G1
+func (s *Setup) Commit(p Polynomial) (*bls.G1, error) {
+	if len(p) != s.n {
+		return nil, fmt.Errorf("err kzg:polynomial of %d evaluations for a setup of %d", len(p), s.n)
+	}
+	c := new(bls.G1)
+	if err := bls.G1MulVec(c, s.g1Lagrange, p); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Evaluate returns p(z)
+func (s *Setup) Evaluate(p Polynomial, z *bls.Fr) (*bls.Fr, error) {
+	if len(p) != s.n {
+		return nil, fmt.Errorf("err kzg:polynomial of %d evaluations for a setup of %d", len(p), s.n)
+	}
+	y := new(bls.Fr)
+	if m := s.index(z); m >= 0 {
+		*y = p[m]
+		return y, nil
+	}
+	// p(z) = (z^n - 1) / n * sum_i p_i * w_i / (z - w_i)
+	den := make([]bls.Fr, s.n)
+	for i := range den {
+		bls.FrSub(&den[i], z, &s.roots[i])
+	}
+	batchInv(den, den)
+	var t bls.Fr
+	for i := range p {
+		bls.FrMul(&t, &p[i], &s.roots[i])
+		bls.FrMul(&t, &t, &den[i])
+		bls.FrAdd(y, y, &t)
+	}
+	var one bls.Fr
+	one.SetInt64(1)
+	frPow(&t, z, uint64(s.n))
+	bls.FrSub(&t, &t, &one)
+	bls.FrMul(y, y, &t)
+	bls.FrMul(y, y, &s.nInv)
+	return y, nil
+}
+
+// Open returns the proof that p(z) = y and y
+func (s *Setup) Open(p Polynomial, z *bls.Fr) (*bls.G1, *bls.Fr, error) {
+	y, err := s.Evaluate(p, z)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := s.index(z)
+	// q_i = (p_i - y) / (w_i - z)
+	den := make([]bls.Fr, s.n)
+	for i := range den {
+		if i == m {
+			den[i].SetInt64(1)
+		} else {
+			bls.FrSub(&den[i], &s.roots[i], z)
+		}
+	}
+	batchInv(den, den)
+	q := make(Polynomial, s.n)
+	for i := range q {
+		bls.FrSub(&q[i], &p[i], y)
+		bls.FrMul(&q[i], &q[i], &den[i])
+	}
+	if m >= 0 {
+		// q(w_m) = sum_{i != m} (p_i - y) * w_i / (z * (z - w_i)) = -1/z * sum_{i != m} q_i * w_i
+		var sum, t bls.Fr
+		for i := range q {
+			if i != m {
+				bls.FrMul(&t, &q[i], &s.roots[i])
+				bls.FrAdd(&sum, &sum, &t)
+			}
+		}
+		bls.FrDiv(&sum, &sum, z)
+		bls.FrNeg(&q[m], &sum)
+	}
+	proof, err := s.Commit(q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return proof, y, nil
+}
+
+// Verify checks the proof that the polynomial committed to by commitment evaluates to y at z
+func (s *Setup) Verify(commitment *bls.G1, z *bls.Fr, y *bls.Fr, proof *bls.G1) bool {
+	// e(C - [y]G1, -G2) * e(proof, [tau - z]G2) = 1
+	var cy, t bls.G1
+	bls.G1Mul(&t, &s.g1, y)
+	bls.G1Sub(&cy, commitment, &t)
+	var negG2, xz, t2 bls.G2
+	bls.G2Neg(&negG2, &s.g2[0])
+	bls.G2Mul(&t2, &s.g2[0], z)
+	bls.G2Sub(&xz, &s.g2[1], &t2)
+	return pairingCheck(&cy, &negG2, proof, &xz)
+}
+
+// OpenMulti returns the proof of the evaluations of p at the distinct points zs and the evaluations
+// zs must not be in the domain and have at most MaxOpeningPoints elements
+func (s *Setup) OpenMulti(p Polynomial, zs []bls.Fr) (*bls.G1, []bls.Fr, error) {
+	if err := s.checkPoints(zs); err != nil {
+		return nil, nil, err
+	}
+	ys := make([]bls.Fr, len(zs))
+	for j := range zs {
+		if s.index(&zs[j]) >= 0 {
+			return nil, nil, fmt.Errorf("err kzg:opening point %d in the domain", j)
+		}
+		y, err := s.Evaluate(p, &zs[j])
+		if err != nil {
+			return nil, nil, err
+		}
+		ys[j] = *y
+	}
+	// q_i = (p_i - I(w_i)) / Z_S(w_i)
+	iCoef := interpolate(zs, ys)
+	zCoef := vanishing(zs)
+	den := make([]bls.Fr, s.n)
+	q := make(Polynomial, s.n)
+	for i := range q {
+		horner(&den[i], zCoef, &s.roots[i])
+		horner(&q[i], iCoef, &s.roots[i])
+		bls.FrSub(&q[i], &p[i], &q[i])
+	}
+	batchInv(den, den)
+	for i := range q {
+		bls.FrMul(&q[i], &q[i], &den[i])
+	}
+	proof, err := s.Commit(q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return proof, ys, nil
+}
+
+// VerifyMulti checks the proof that the polynomial committed to by commitment evaluates to ys at zs
+func (s *Setup) VerifyMulti(commitment *bls.G1, zs []bls.Fr, ys []bls.Fr, proof *bls.G1) bool {
+	if len(zs) != len(ys) || s.checkPoints(zs) != nil {
+		return false
+	}
+	// e(C - [I(tau)]G1, -G2) * e(proof, [Z_S(tau)]G2) = 1
+	iCoef := interpolate(zs, ys)
+	evals := make(Polynomial, s.n)
+	for i := range evals {
+		horner(&evals[i], iCoef, &s.roots[i])
+	}
+	commitI, err := s.Commit(evals)
+	if err != nil {
+		return false
+	}
+	var ci bls.G1
+	bls.G1Sub(&ci, commitment, commitI)
+	zCoef := vanishing(zs)
+	var zTau, negG2 bls.G2
+	if err := bls.G2MulVec(&zTau, s.g2[:len(zCoef)], zCoef); err != nil {
+		return false
+	}
+	bls.G2Neg(&negG2, &s.g2[0])
+	return pairingCheck(&ci, &negG2, proof, &zTau)
+}
+
+// verifyBatch checks the proofs that commitments[i] evaluates to ys[i] at zs[i], combined with the powers of r
+func (s *Setup) verifyBatch(commitments []bls.G1, zs []bls.Fr, ys []bls.Fr, proofs []bls.G1, r *bls.Fr) bool {
+	// e(sum r^i proof_i, -[tau]G2) * e(sum r^i (C_i - [y_i]G1 + [z_i]proof_i), G2) = 1
+	n := len(commitments)
+	powers := make([]bls.Fr, n)
+	zPowers := make([]bls.Fr, n)
+	cy := make([]bls.G1, n)
+	var rPow bls.Fr
+	rPow.SetInt64(1)
+	var t bls.G1
+	for i := 0; i < n; i++ {
+		powers[i] = rPow
+		bls.FrMul(&zPowers[i], &zs[i], &rPow)
+		bls.G1Mul(&t, &s.g1, &ys[i])
+		bls.G1Sub(&cy[i], &commitments[i], &t)
+		bls.FrMul(&rPow, &rPow, r)
+	}
+	var proofSum, proofZSum, cySum bls.G1
+	if bls.G1MulVec(&proofSum, proofs, powers) != nil || bls.G1MulVec(&proofZSum, proofs, zPowers) != nil || bls.G1MulVec(&cySum, cy, powers) != nil {
+		return false
+	}
+	bls.G1Add(&cySum, &cySum, &proofZSum)
+	var negTau bls.G2
+	bls.G2Neg(&negTau, &s.g2[1])
+	return pairingCheck(&proofSum, &negTau, &cySum, &s.g2[0])
+}
+
+// index -- the position of z in the domain, -1 if z is not a root of unity of the domain
+func (s *Setup) index(z *bls.Fr) int {
+	for i := range s.roots {
+		if s.roots[i].IsEqual(z) {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkPoints -- 1 to MaxOpeningPoints distinct points
+func (s *Setup) checkPoints(zs []bls.Fr) error {
+	if len(zs) == 0 || len(zs) > s.MaxOpeningPoints() {
+		return fmt.Errorf("err kzg:%d opening points out of 1 to %d", len(zs), s.MaxOpeningPoints())
+	}
+	seen := make(map[string]bool, len(zs))
+	for j := range zs {
+		k := string(zs[j].Serialize())
+		if seen[k] {
+			return fmt.Errorf("err kzg:duplicate opening point %d", j)
+		}
+		seen[k] = true
+	}
+	return nil
+}
+
+// pairingCheck -- e(p1, q1) * e(p2, q2) = 1
+func pairingCheck(p1 *bls.G1, q1 *bls.G2, p2 *bls.G1, q2 *bls.G2) bool {
+	var e, t bls.GT
+	bls.MillerLoop(&e, p1, q1)
+	bls.MillerLoop(&t, p2, q2)
+	bls.GTMul(&e, &e, &t)
+	bls.FinalExp(&e, &e)
+	return e.IsOne()
+}
+
+// rootsOfUnity -- the powers of a primitive n-th root of unity in natural order
+func rootsOfUnity(n int) []bls.Fr {
+	var g, w bls.Fr
+	g.SetInt64(primitiveRoot)
+	e := new(big.Int).Sub(curveOrder, big.NewInt(1))
+	frExp(&w, &g, e.Div(e, big.NewInt(int64(n))))
+	roots := make([]bls.Fr, n)
+	roots[0].SetInt64(1)
+	for i := 1; i < n; i++ {
+		bls.FrMul(&roots[i], &roots[i-1], &w)
+	}
+	return roots
+}
+
+// reverseBits -- the bit-reversal permutation of i for a power of two n
+func reverseBits(i int, n int) int {
+	if n == 1 {
+		return 0
+	}
+	return int(bits.Reverse64(uint64(i)) >> (64 - uint(bits.TrailingZeros64(uint64(n)))))
+}
+
+// batchInv sets out[i] = 1 / x[i] for non zero x, out may be x
+func batchInv(out []bls.Fr, x []bls.Fr) {
+	prefix := make([]bls.Fr, len(x))
+	var acc bls.Fr
+	acc.SetInt64(1)
+	for i := range x {
+		prefix[i] = acc
+		bls.FrMul(&acc, &acc, &x[i])
+	}
+	bls.FrInv(&acc, &acc)
+	for i := len(x) - 1; i >= 0; i-- {
+		var t bls.Fr
+		bls.FrMul(&t, &acc, &prefix[i])
+		bls.FrMul(&acc, &acc, &x[i])
+		out[i] = t
+	}
+}
+
+func frPow(out *bls.Fr, x *bls.Fr, e uint64) {
+	frExp(out, x, new(big.Int).SetUint64(e))
+}
+
+func frExp(out *bls.Fr, x *bls.Fr, e *big.Int) {
+	var z bls.Fr
+	z.SetInt64(1)
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		bls.FrMul(&z, &z, &z)
+		if e.Bit(i) == 1 {
+			bls.FrMul(&z, &z, x)
+		}
+	}
+	*out = z
+}
+
+// horner -- out = sum_j c[j] x^j
+func horner(out *bls.Fr, c []bls.Fr, x *bls.Fr) {
+	var y bls.Fr
+	for j := len(c) - 1; j >= 0; j-- {
+		bls.FrMul(&y, &y, x)
+		bls.FrAdd(&y, &y, &c[j])
+	}
+	*out = y
+}
+
+// vanishing -- the coefficients of Z_S(X) = prod_j (X - zs[j])
+func vanishing(zs []bls.Fr) []bls.Fr {
+	c := make([]bls.Fr, len(zs)+1)
+	c[0].SetInt64(1)
+	for j := range zs {
+		var t bls.Fr
+		for k := j + 1; k > 0; k-- {
+			bls.FrMul(&t, &c[k], &zs[j])
+			bls.FrSub(&c[k], &c[k-1], &t)
+		}
+		bls.FrMul(&t, &c[0], &zs[j])
+		bls.FrNeg(&c[0], &t)
+	}
+	return c
+}
+
+// interpolate -- the coefficients of the polynomial of degree below len(zs) with I(zs[j]) = ys[j]
+func interpolate(zs []bls.Fr, ys []bls.Fr) []bls.Fr {
+	k := len(zs)
+	z := vanishing(zs)
+	out := make([]bls.Fr, k)
+	num := make([]bls.Fr, k)
+	for j := range zs {
+		// num = Z_S(X) / (X - zs[j])
+		num[k-1] = z[k]
+		for i := k - 1; i > 0; i-- {
+			bls.FrMul(&num[i-1], &num[i], &zs[j])
+			bls.FrAdd(&num[i-1], &num[i-1], &z[i])
+		}
+		var scale, t bls.Fr
+		horner(&scale, num, &zs[j])
+		bls.FrDiv(&scale, &ys[j], &scale)
+		for i := range num {
+			bls.FrMul(&t, &num[i], &scale)
+			bls.FrAdd(&out[i], &out[i], &t)
+		}
+	}
+	return out
+}
+
+func mustHex(s string) []byte {
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return buf
+}
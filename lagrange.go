@@ -0,0 +1,116 @@
+package bls
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// ---------------- Lagrange Basis --------------------
+
+// LagrangeBasis holds the Lagrange coefficients at 0 for a fixed set of ids.
+// Computing it once lets the same signer set recover many values without
+// recomputing the coefficients on every call.
+type LagrangeBasis struct {
+	idVec  []ID
+	coeffs []Fr
+}
+
+// NewLagrangeBasis computes the Lagrange coefficients for idVec
+// Returns an error if idVec is empty, contains a zero id or contains duplicates
+func NewLagrangeBasis(idVec []ID) (*LagrangeBasis, error) {
+	n := len(idVec)
+	if n == 0 {
+		return nil, fmt.Errorf("err NewLagrangeBasis:empty id vector")
+	}
+	// lambda_i = prod_j x_j / (x_i * prod_{j != i} (x_j - x_i))
+	var prod, t Fr
+	prod.SetInt64(1)
+	den := make([]Fr, n)
+	for i := 0; i < n; i++ {
+		FrMul(&prod, &prod, &idVec[i].v)
+		den[i] = idVec[i].v
+		for j := 0; j < n; j++ {
+			if j == i {
+				continue
+			}
+			FrSub(&t, &idVec[j].v, &idVec[i].v)
+			FrMul(&den[i], &den[i], &t)
+		}
+		if den[i].IsZero() {
+			return nil, fmt.Errorf("err NewLagrangeBasis:zero or duplicate id %s", idVec[i].GetHexString())
+		}
+	}
+	coeffs := make([]Fr, n)
+	frBatchInv(coeffs, den)
+	for i := 0; i < n; i++ {
+		FrMul(&coeffs[i], &coeffs[i], &prod)
+	}
+	ids := make([]ID, n)
+	copy(ids, idVec)
+	return &LagrangeBasis{idVec: ids, coeffs: coeffs}, nil
+}
+
+// Len returns the number of ids in the basis
+func (basis *LagrangeBasis) Len() int {
+	return len(basis.idVec)
+}
+
+// IDs returns a copy of the ids the basis was computed for
+func (basis *LagrangeBasis) IDs() []ID {
+	ids := make([]ID, len(basis.idVec))
+	copy(ids, basis.idVec)
+	return ids
+}
+
+// frBatchInv sets out[i] = 1 / x[i] using a single field inversion
+// All x[i] must be non-zero and out must not alias x
+func frBatchInv(out []Fr, x []Fr) {
+	n := len(x)
+	if n == 0 {
+		return
+	}
+	// out[i] = x[0] * ... * x[i - 1]
+	out[0].SetInt64(1)
+	for i := 1; i < n; i++ {
+		FrMul(&out[i], &out[i-1], &x[i-1])
+	}
+	var inv Fr
+	FrMul(&inv, &out[n-1], &x[n-1])
+	FrInv(&inv, &inv)
+	for i := n - 1; i >= 0; i-- {
+		FrMul(&out[i], &out[i], &inv)
+		FrMul(&inv, &inv, &x[i])
+	}
+}
+
+// RecoverWithBasis -- like Recover, using precomputed coefficients; secVec[i] must belong to basis id i
+func (sec *SecretKey) RecoverWithBasis(secVec []SecretKey, basis *LagrangeBasis) error {
+	if len(secVec) != basis.Len() {
+		return fmt.Errorf("err SecretKey.RecoverWithBasis:bad size")
+	}
+	var t Fr
+	sec.v.Clear()
+	for i := range secVec {
+		FrMul(&t, &secVec[i].v, &basis.coeffs[i])
+		FrAdd(&sec.v, &sec.v, &t)
+	}
+	return nil
+}
+
+// RecoverWithBasis -- like Recover, using precomputed coefficients; pubVec[i] must belong to basis id i
+func (pub *PublicKey) RecoverWithBasis(pubVec []PublicKey, basis *LagrangeBasis) error {
+	if len(pubVec) != basis.Len() {
+		return fmt.Errorf("err PublicKey.RecoverWithBasis:bad size")
+	}
+	// #nosec
+	return G2MulVec(&pub.v, *(*[]G2)(unsafe.Pointer(&pubVec)), basis.coeffs)
+}
+
+// RecoverWithBasis -- like Recover, using precomputed coefficients; signVec[i] must belong to basis id i
+func (sign *Sign) RecoverWithBasis(signVec []Sign, basis *LagrangeBasis) error {
+	if len(signVec) != basis.Len() {
+		return fmt.Errorf("err Sign.RecoverWithBasis:bad size")
+	}
+	// #nosec
+	return G1MulVec(&sign.v, *(*[]G1)(unsafe.Pointer(&signVec)), basis.coeffs)
+}
@@ -68,6 +68,26 @@ func GetFieldOrder() string {
 	return string(buf[:n])
 }
 
+// bufPointer -- pointer to the input buf for mcl, nil if buf is empty
+func bufPointer(buf []byte) unsafe.Pointer {
+	if len(buf) == 0 {
+		return nil
+	}
+	// #nosec
+	return unsafe.Pointer(&buf[0])
+}
+
+// SetETHserialization --
+// switch the serialization of BLS12-381 points to the compressed format of ZCash and Ethereum
+// the mode is global and not thread safe: set it before using the library
+func SetETHserialization(enable bool) {
+	v := 0
+	if enable {
+		v = 1
+	}
+	C.mclBn_setETHserialization(C.int(v))
+}
+
 // Fr --
 type Fr struct {
 	v C.mclBnFr
@@ -95,7 +115,7 @@ func (x *Fr) SetInt64(v int64) {
 func (x *Fr) SetString(s string, base int) error {
 	buf := []byte(s)
 	// #nosec
-	err := C.mclBnFr_setStr(x.getPointer(), (*C.char)(unsafe.Pointer(&buf[0])), C.size_t(len(buf)), C.int(base))
+	err := C.mclBnFr_setStr(x.getPointer(), (*C.char)(bufPointer(buf)), C.size_t(len(buf)), C.int(base))
 	if err != 0 {
 		return fmt.Errorf("err mclBnFr_setStr %x", err)
 	}
@@ -105,8 +125,8 @@ func (x *Fr) SetString(s string, base int) error {
 // Deserialize --
 func (x *Fr) Deserialize(buf []byte) error {
 	// #nosec
-	err := C.mclBnFr_deserialize(x.getPointer(), unsafe.Pointer(&buf[0]), C.size_t(len(buf)))
-	if err == 0 {
+	n := C.mclBnFr_deserialize(x.getPointer(), bufPointer(buf), C.size_t(len(buf)))
+	if n == 0 || int(n) != len(buf) {
 		return fmt.Errorf("err mclBnFr_deserialize %x", buf)
 	}
 	return nil
@@ -115,13 +135,23 @@ func (x *Fr) Deserialize(buf []byte) error {
 // SetLittleEndian --
 func (x *Fr) SetLittleEndian(buf []byte) error {
 	// #nosec
-	err := C.mclBnFr_setLittleEndian(x.getPointer(), unsafe.Pointer(&buf[0]), C.size_t(len(buf)))
+	err := C.mclBnFr_setLittleEndian(x.getPointer(), bufPointer(buf), C.size_t(len(buf)))
 	if err != 0 {
 		return fmt.Errorf("err mclBnFr_setLittleEndian %x", err)
 	}
 	return nil
 }
 
+// SetLittleEndianMod -- set (buf mod r) where buf is at most 64 bytes
+func (x *Fr) SetLittleEndianMod(buf []byte) error {
+	// #nosec
+	err := C.mclBnFr_setLittleEndianMod(x.getPointer(), bufPointer(buf), C.size_t(len(buf)))
+	if err != 0 {
+		return fmt.Errorf("err mclBnFr_setLittleEndianMod %x", err)
+	}
+	return nil
+}
+
 // IsEqual --
 func (x *Fr) IsEqual(rhs *Fr) bool {
 	return C.mclBnFr_isEqual(x.getPointer(), rhs.getPointer()) == 1
@@ -137,8 +167,10 @@ func (x *Fr) IsOne() bool {
 	return C.mclBnFr_isOne(x.getPointer()) == 1
 }
 
-// SetByCSPRNG --
+// SetByCSPRNG -- uses the reader set by SetRandReader if any
 func (x *Fr) SetByCSPRNG() {
+	randLock.RLock()
+	defer randLock.RUnlock()
 	err := C.mclBnFr_setByCSPRNG(x.getPointer())
 	if err != 0 {
 		panic("err mclBnFr_setByCSPRNG")
@@ -148,7 +180,7 @@ func (x *Fr) SetByCSPRNG() {
 // SetHashOf --
 func (x *Fr) SetHashOf(buf []byte) bool {
 	// #nosec
-	return C.mclBnFr_setHashOf(x.getPointer(), unsafe.Pointer(&buf[0]), C.size_t(len(buf))) == 0
+	return C.mclBnFr_setHashOf(x.getPointer(), bufPointer(buf), C.size_t(len(buf))) == 0
 }
 
 // GetString --
@@ -224,7 +256,7 @@ func (x *G1) Clear() {
 func (x *G1) SetString(s string, base int) error {
 	buf := []byte(s)
 	// #nosec
-	err := C.mclBnG1_setStr(x.getPointer(), (*C.char)(unsafe.Pointer(&buf[0])), C.size_t(len(buf)), C.int(base))
+	err := C.mclBnG1_setStr(x.getPointer(), (*C.char)(bufPointer(buf)), C.size_t(len(buf)), C.int(base))
 	if err != 0 {
 		return fmt.Errorf("err mclBnG1_setStr %x", err)
 	}
@@ -234,8 +266,8 @@ func (x *G1) SetString(s string, base int) error {
 // Deserialize --
 func (x *G1) Deserialize(buf []byte) error {
 	// #nosec
-	err := C.mclBnG1_deserialize(x.getPointer(), unsafe.Pointer(&buf[0]), C.size_t(len(buf)))
-	if err == 0 {
+	n := C.mclBnG1_deserialize(x.getPointer(), bufPointer(buf), C.size_t(len(buf)))
+	if n == 0 || int(n) != len(buf) {
 		return fmt.Errorf("err mclBnG1_deserialize %x", buf)
 	}
 	return nil
@@ -251,10 +283,20 @@ func (x *G1) IsZero() bool {
 	return C.mclBnG1_isZero(x.getPointer()) == 1
 }
 
+// IsValid -- true if x is on the curve
+func (x *G1) IsValid() bool {
+	return C.mclBnG1_isValid(x.getPointer()) == 1
+}
+
+// IsValidOrder -- true if x is in the subgroup of order r
+func (x *G1) IsValidOrder() bool {
+	return C.mclBnG1_isValidOrder(x.getPointer()) == 1
+}
+
 // HashAndMapTo --
 func (x *G1) HashAndMapTo(buf []byte) error {
 	// #nosec
-	err := C.mclBnG1_hashAndMapTo(x.getPointer(), unsafe.Pointer(&buf[0]), C.size_t(len(buf)))
+	err := C.mclBnG1_hashAndMapTo(x.getPointer(), bufPointer(buf), C.size_t(len(buf)))
 	if err != 0 {
 		return fmt.Errorf("err mclBnG1_hashAndMapTo %x", err)
 	}
@@ -334,7 +376,7 @@ func (x *G2) Clear() {
 func (x *G2) SetString(s string, base int) error {
 	buf := []byte(s)
 	// #nosec
-	err := C.mclBnG2_setStr(x.getPointer(), (*C.char)(unsafe.Pointer(&buf[0])), C.size_t(len(buf)), C.int(base))
+	err := C.mclBnG2_setStr(x.getPointer(), (*C.char)(bufPointer(buf)), C.size_t(len(buf)), C.int(base))
 	if err != 0 {
 		return fmt.Errorf("err mclBnG2_setStr %x", err)
 	}
@@ -344,8 +386,8 @@ func (x *G2) SetString(s string, base int) error {
 // Deserialize --
 func (x *G2) Deserialize(buf []byte) error {
 	// #nosec
-	err := C.mclBnG2_deserialize(x.getPointer(), unsafe.Pointer(&buf[0]), C.size_t(len(buf)))
-	if err == 0 {
+	n := C.mclBnG2_deserialize(x.getPointer(), bufPointer(buf), C.size_t(len(buf)))
+	if n == 0 || int(n) != len(buf) {
 		return fmt.Errorf("err mclBnG2_deserialize %x", buf)
 	}
 	return nil
@@ -361,10 +403,20 @@ func (x *G2) IsZero() bool {
 	return C.mclBnG2_isZero(x.getPointer()) == 1
 }
 
+// IsValid -- true if x is on the curve
+func (x *G2) IsValid() bool {
+	return C.mclBnG2_isValid(x.getPointer()) == 1
+}
+
+// IsValidOrder -- true if x is in the subgroup of order r
+func (x *G2) IsValidOrder() bool {
+	return C.mclBnG2_isValidOrder(x.getPointer()) == 1
+}
+
 // HashAndMapTo --
 func (x *G2) HashAndMapTo(buf []byte) error {
 	// #nosec
-	err := C.mclBnG2_hashAndMapTo(x.getPointer(), unsafe.Pointer(&buf[0]), C.size_t(len(buf)))
+	err := C.mclBnG2_hashAndMapTo(x.getPointer(), bufPointer(buf), C.size_t(len(buf)))
 	if err != 0 {
 		return fmt.Errorf("err mclBnG2_hashAndMapTo %x", err)
 	}
@@ -445,7 +497,7 @@ func (x *GT) SetInt64(v int64) {
 func (x *GT) SetString(s string, base int) error {
 	buf := []byte(s)
 	// #nosec
-	err := C.mclBnGT_setStr(x.getPointer(), (*C.char)(unsafe.Pointer(&buf[0])), C.size_t(len(buf)), C.int(base))
+	err := C.mclBnGT_setStr(x.getPointer(), (*C.char)(bufPointer(buf)), C.size_t(len(buf)), C.int(base))
 	if err != 0 {
 		return fmt.Errorf("err mclBnGT_setStr %x", err)
 	}
@@ -455,8 +507,8 @@ func (x *GT) SetString(s string, base int) error {
 // Deserialize --
 func (x *GT) Deserialize(buf []byte) error {
 	// #nosec
-	err := C.mclBnGT_deserialize(x.getPointer(), unsafe.Pointer(&buf[0]), C.size_t(len(buf)))
-	if err == 0 {
+	n := C.mclBnGT_deserialize(x.getPointer(), bufPointer(buf), C.size_t(len(buf)))
+	if n == 0 || int(n) != len(buf) {
 		return fmt.Errorf("err mclBnGT_deserialize %x", buf)
 	}
 	return nil
@@ -574,6 +626,9 @@ func PrecomputedMillerLoop2(out *GT, P1 *G1, Q1buf []uint64, P2 *G1, Q2buf []uin
 
 // FrEvaluatePolynomial -- y = c[0] + c[1] * x + c[2] * x^2 + ...
 func FrEvaluatePolynomial(y *Fr, c []Fr, x *Fr) error {
+	if len(c) == 0 {
+		return fmt.Errorf("err FrEvaluatePolynomial:empty polynomial")
+	}
 	// #nosec
 	err := C.mclBn_FrEvaluatePolynomial(y.getPointer(), (*C.mclBnFr)(unsafe.Pointer(&c[0])), (C.size_t)(len(c)), x.getPointer())
 	if err != 0 {
@@ -584,6 +639,9 @@ func FrEvaluatePolynomial(y *Fr, c []Fr, x *Fr) error {
 
 // G1EvaluatePolynomial -- y = c[0] + c[1] * x + c[2] * x^2 + ...
 func G1EvaluatePolynomial(y *G1, c []G1, x *Fr) error {
+	if len(c) == 0 {
+		return fmt.Errorf("err G1EvaluatePolynomial:empty polynomial")
+	}
 	// #nosec
 	err := C.mclBn_G1EvaluatePolynomial(y.getPointer(), (*C.mclBnG1)(unsafe.Pointer(&c[0])), (C.size_t)(len(c)), x.getPointer())
 	if err != 0 {
@@ -594,6 +652,9 @@ func G1EvaluatePolynomial(y *G1, c []G1, x *Fr) error {
 
 // G2EvaluatePolynomial -- y = c[0] + c[1] * x + c[2] * x^2 + ...
 func G2EvaluatePolynomial(y *G2, c []G2, x *Fr) error {
+	if len(c) == 0 {
+		return fmt.Errorf("err G2EvaluatePolynomial:empty polynomial")
+	}
 	// #nosec
 	err := C.mclBn_G2EvaluatePolynomial(y.getPointer(), (*C.mclBnG2)(unsafe.Pointer(&c[0])), (C.size_t)(len(c)), x.getPointer())
 	if err != 0 {
@@ -604,7 +665,7 @@ func G2EvaluatePolynomial(y *G2, c []G2, x *Fr) error {
 
 // FrLagrangeInterpolation --
 func FrLagrangeInterpolation(out *Fr, xVec []Fr, yVec []Fr) error {
-	if len(xVec) != len(yVec) {
+	if len(xVec) == 0 || len(xVec) != len(yVec) {
 		return fmt.Errorf("err FrLagrangeInterpolation:bad size")
 	}
 	// #nosec
@@ -617,7 +678,7 @@ func FrLagrangeInterpolation(out *Fr, xVec []Fr, yVec []Fr) error {
 
 // G1LagrangeInterpolation --
 func G1LagrangeInterpolation(out *G1, xVec []Fr, yVec []G1) error {
-	if len(xVec) != len(yVec) {
+	if len(xVec) == 0 || len(xVec) != len(yVec) {
 		return fmt.Errorf("err G1LagrangeInterpolation:bad size")
 	}
 	// #nosec
@@ -630,7 +691,7 @@ func G1LagrangeInterpolation(out *G1, xVec []Fr, yVec []G1) error {
 
 // G2LagrangeInterpolation --
 func G2LagrangeInterpolation(out *G2, xVec []Fr, yVec []G2) error {
-	if len(xVec) != len(yVec) {
+	if len(xVec) == 0 || len(xVec) != len(yVec) {
 		return fmt.Errorf("err G2LagrangeInterpolation:bad size")
 	}
 	// #nosec
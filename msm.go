@@ -0,0 +1,131 @@
+package bls
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// msmNaiveThreshold -- below this size a plain sum of multiplications is faster than bucketing
+const msmNaiveThreshold = 8
+
+// G1MulVec -- out = sum_i xVec[i] * yVec[i]
+func G1MulVec(out *G1, xVec []G1, yVec []Fr) error {
+	if len(xVec) != len(yVec) {
+		return fmt.Errorf("err G1MulVec:bad size")
+	}
+	out.Clear()
+	n := len(xVec)
+	if n < msmNaiveThreshold {
+		var t G1
+		for i := 0; i < n; i++ {
+			G1Mul(&t, &xVec[i], &yVec[i])
+			G1Add(out, out, &t)
+		}
+		return nil
+	}
+	scalars, bitLen := msmScalars(yVec)
+	c := msmWindow(n)
+	buckets := make([]G1, (1<<c)-1)
+	var running, sum G1
+	for w := (bitLen+c-1)/c - 1; w >= 0; w-- {
+		for k := 0; k < c; k++ {
+			G1Dbl(out, out)
+		}
+		for j := range buckets {
+			buckets[j].Clear()
+		}
+		for i := 0; i < n; i++ {
+			if d := msmDigit(scalars[i], w*c, c); d != 0 {
+				G1Add(&buckets[d-1], &buckets[d-1], &xVec[i])
+			}
+		}
+		running.Clear()
+		sum.Clear()
+		for j := len(buckets) - 1; j >= 0; j-- {
+			G1Add(&running, &running, &buckets[j])
+			G1Add(&sum, &sum, &running)
+		}
+		G1Add(out, out, &sum)
+	}
+	return nil
+}
+
+// G2MulVec -- out = sum_i xVec[i] * yVec[i]
+func G2MulVec(out *G2, xVec []G2, yVec []Fr) error {
+	if len(xVec) != len(yVec) {
+		return fmt.Errorf("err G2MulVec:bad size")
+	}
+	out.Clear()
+	n := len(xVec)
+	if n < msmNaiveThreshold {
+		var t G2
+		for i := 0; i < n; i++ {
+			G2Mul(&t, &xVec[i], &yVec[i])
+			G2Add(out, out, &t)
+		}
+		return nil
+	}
+	scalars, bitLen := msmScalars(yVec)
+	c := msmWindow(n)
+	buckets := make([]G2, (1<<c)-1)
+	var running, sum G2
+	for w := (bitLen+c-1)/c - 1; w >= 0; w-- {
+		for k := 0; k < c; k++ {
+			G2Dbl(out, out)
+		}
+		for j := range buckets {
+			buckets[j].Clear()
+		}
+		for i := 0; i < n; i++ {
+			if d := msmDigit(scalars[i], w*c, c); d != 0 {
+				G2Add(&buckets[d-1], &buckets[d-1], &xVec[i])
+			}
+		}
+		running.Clear()
+		sum.Clear()
+		for j := len(buckets) - 1; j >= 0; j-- {
+			G2Add(&running, &running, &buckets[j])
+			G2Add(&sum, &sum, &running)
+		}
+		G2Add(out, out, &sum)
+	}
+	return nil
+}
+
+// msmScalars returns the little-endian encodings of yVec and their common bit length
+func msmScalars(yVec []Fr) ([][]byte, int) {
+	scalars := make([][]byte, len(yVec))
+	maxLen := 0
+	for i := range yVec {
+		scalars[i] = yVec[i].Serialize()
+		if len(scalars[i]) > maxLen {
+			maxLen = len(scalars[i])
+		}
+	}
+	return scalars, maxLen * 8
+}
+
+// msmWindow returns the bucket window size in bits for n points
+func msmWindow(n int) int {
+	c := bits.Len(uint(n)) - 2
+	if c < 2 {
+		return 2
+	}
+	if c > 12 {
+		return 12
+	}
+	return c
+}
+
+// msmDigit returns the c bits of the little-endian scalar s starting at bit offset
+func msmDigit(s []byte, offset int, c int) int {
+	d := 0
+	for k := 0; k < c; k++ {
+		bit := offset + k
+		if bit/8 >= len(s) {
+			break
+		}
+		d |= int((s[bit/8]>>(uint(bit)%8))&1) << uint(k)
+	}
+	return d
+}
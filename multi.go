@@ -0,0 +1,108 @@
+package bls
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// ---------------- Multisignatures --------------------
+// Boneh-Drijvers-Neven multisignatures of a message, secure against rogue keys without pops.
+// Every key of the signing set L = (pub_1 .. pub_n) gets a 128-bit coefficient bound to the whole set
+//
+//	t_i = SHA-256(multiCoefficientDst | SHA-256(pub_1 | .. | pub_n) | i | pub_i)[:16]
+//
+// and the signatures and keys are aggregated with these coefficients
+//
+//	aggSig = sum_i t_i * sig_i, aggPub = sum_i t_i * pub_i
+//
+// so aggSig verifies with Sign.Verify under aggPub. Signers and verifiers must list the keys
+// in the same order.
+
+// multiCoefficientDst -- domain separation of the coefficient hash
+const multiCoefficientDst = "BLS-BDN-COEFFICIENT-V1"
+
+// MultiCoefficients returns the coefficients of the keys of pubVec
+func MultiCoefficients(pubVec []PublicKey) ([]Fr, error) {
+	n := len(pubVec)
+	if n == 0 {
+		return nil, fmt.Errorf("err MultiCoefficients:no public keys")
+	}
+	serialized := make([][]byte, n)
+	h := sha256.New()
+	for i := range pubVec {
+		serialized[i] = pubVec[i].Serialize()
+		h.Write(serialized[i])
+	}
+	set := h.Sum(nil)
+	coefVec := make([]Fr, n)
+	var index [8]byte
+	for i := range pubVec {
+		h.Reset()
+		h.Write([]byte(multiCoefficientDst))
+		h.Write(set)
+		binary.BigEndian.PutUint64(index[:], uint64(i))
+		h.Write(index[:])
+		h.Write(serialized[i])
+		if err := coefVec[i].SetLittleEndian(h.Sum(nil)[:16]); err != nil {
+			return nil, err
+		}
+	}
+	return coefVec, nil
+}
+
+// AggregateMulti returns the multisignature of sigVec, sigVec[i] signing the message with pubVec[i]
+func AggregateMulti(pubVec []PublicKey, sigVec []Sign) (*Sign, error) {
+	if len(pubVec) != len(sigVec) {
+		return nil, fmt.Errorf("err AggregateMulti:bad size")
+	}
+	coefVec, err := MultiCoefficients(pubVec)
+	if err != nil {
+		return nil, err
+	}
+	xVec := make([]G1, len(sigVec))
+	for i := range sigVec {
+		xVec[i] = sigVec[i].v
+	}
+	sign := new(Sign)
+	if err := G1MulVec(&sign.v, xVec, coefVec); err != nil {
+		return nil, err
+	}
+	return sign, nil
+}
+
+// AggregatePublicKeysMulti returns the key verifying the multisignatures of pubVec
+// It can be computed once for a fixed signing set
+func AggregatePublicKeysMulti(pubVec []PublicKey) (*PublicKey, error) {
+	coefVec, err := MultiCoefficients(pubVec)
+	if err != nil {
+		return nil, err
+	}
+	xVec := make([]G2, len(pubVec))
+	for i := range pubVec {
+		xVec[i] = pubVec[i].v
+	}
+	pub := new(PublicKey)
+	if err := G2MulVec(&pub.v, xVec, coefVec); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+// VerifyMulti verifies a multisignature of message by all keys of pubVec
+// It is false if a key is zero or outside the subgroup
+func (sign *Sign) VerifyMulti(pubVec []PublicKey, message []byte) bool {
+	if len(message) == 0 {
+		return false
+	}
+	for i := range pubVec {
+		if pubVec[i].v.IsZero() || !pubVec[i].v.IsValidOrder() {
+			return false
+		}
+	}
+	pub, err := AggregatePublicKeysMulti(pubVec)
+	if err != nil {
+		return false
+	}
+	return sign.Verify(pub, message)
+}
@@ -0,0 +1,78 @@
+package bls
+
+/*
+#include "config.h"
+#include <bls/bls.h>
+typedef unsigned int (*goReadRandFunc)(void *, void *, unsigned int);
+unsigned int goReadRandCgo(void *self, void *buf, unsigned int n);
+*/
+import "C"
+import (
+	"crypto/rand"
+	"io"
+	"sync"
+	"unsafe"
+)
+
+var (
+	// randLock is held for reading by CSPRNG calls and for writing while the reader is replaced
+	randLock sync.RWMutex
+	// randReaderLock serializes reads since randReader need not be safe for concurrent use
+	randReaderLock sync.Mutex
+	randReader     io.Reader
+)
+
+// goReadRand fills buf from randReader and returns n if success else 0
+// it is called by the C library through goReadRandCgo (see rand_cgo.go)
+//
+//export goReadRand
+func goReadRand(buf unsafe.Pointer, n C.uint) C.uint {
+	randReaderLock.Lock()
+	defer randReaderLock.Unlock()
+	if randReader == nil {
+		return 0
+	}
+	// #nosec
+	if _, err := io.ReadFull(randReader, unsafe.Slice((*byte)(buf), int(n))); err != nil {
+		return 0
+	}
+	return n
+}
+
+// SetRandReader routes the CSPRNG calls of the library (Fr.SetByCSPRNG, SecretKey.SetByCSPRNG,
+// NewSecretKey, GetMasterSecretKey...) and ReadRand, which the subpackages use for their random
+// bytes, through r, e.g. a deterministic stream in tests or a hardware RNG device file.
+// A nil r restores the default CSPRNG.
+// If r fails to fill a request the CSPRNG call panics.
+// It returns the previous reader (nil for the default) so that a caller can restore it with
+//
+//	defer bls.SetRandReader(bls.SetRandReader(r))
+//
+// It is safe to call concurrently with CSPRNG calls, which wait until the reader is replaced.
+func SetRandReader(r io.Reader) io.Reader {
+	randLock.Lock()
+	defer randLock.Unlock()
+	prev := randReader
+	randReader = r
+	if r != nil {
+		// #nosec
+		C.blsSetRandFunc(nil, C.goReadRandFunc(unsafe.Pointer(C.goReadRandCgo)))
+	} else {
+		C.blsSetRandFunc(nil, nil)
+	}
+	return prev
+}
+
+// ReadRand fills buf from the reader set by SetRandReader, or from crypto/rand by default
+func ReadRand(buf []byte) error {
+	randLock.RLock()
+	defer randLock.RUnlock()
+	if randReader == nil {
+		_, err := io.ReadFull(rand.Reader, buf)
+		return err
+	}
+	randReaderLock.Lock()
+	defer randReaderLock.Unlock()
+	_, err := io.ReadFull(randReader, buf)
+	return err
+}
@@ -0,0 +1,12 @@
+package bls
+
+/*
+// exported from rand.go, which can't define C functions since it uses //export
+unsigned int goReadRand(void *buf, unsigned int n);
+unsigned int goReadRandCgo(void *self, void *buf, unsigned int n)
+{
+	(void)self;
+	return goReadRand(buf, n);
+}
+*/
+import "C"
@@ -0,0 +1,132 @@
+// Package registry keeps the public keys admitted with a valid proof of possession.
+//
+// Aggregating the public keys of signatures of the same message is only safe against rogue
+// key attacks if every key proved possession of its secret key (SecretKey.GetPop), so the
+// Registry admits keys with a valid pop only and aggregates them by index. Keys get stable
+// indices in registration order and are kept deserialized.
+//
+// A pop is the signature of the serialized public key with the signing hash, so a key
+// holder must never sign its own serialized public key as a message.
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+// ErrInvalidPop is returned when registering a key with an invalid proof of possession
+var ErrInvalidPop = errors.New("err registry:invalid proof of possession")
+
+// ErrInvalidKey is returned when registering the zero public key or a key outside the subgroup
+var ErrInvalidKey = errors.New("err registry:invalid public key")
+
+// Registry -- public keys admitted with a valid pop, safe for concurrent use
+type Registry struct {
+	lock  sync.RWMutex
+	keys  []bls.PublicKey
+	index map[string]uint32
+}
+
+// New returns an empty Registry
+func New() *Registry {
+	return &Registry{index: make(map[string]uint32)}
+}
+
+// Register admits pub if pop is a valid proof of possession for it and returns its index
+// Registering an admitted key again returns its index
+func (r *Registry) Register(pub *bls.PublicKey, pop *bls.Sign) (uint32, error) {
+	p := bls.CastFromPublicKey(pub)
+	if p.IsZero() || !p.IsValidOrder() {
+		return 0, ErrInvalidKey
+	}
+	if !pop.VerifyPop(pub) {
+		return 0, ErrInvalidPop
+	}
+	id := string(pub.Serialize())
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if i, ok := r.index[id]; ok {
+		return i, nil
+	}
+	i := uint32(len(r.keys))
+	r.keys = append(r.keys, *pub)
+	r.index[id] = i
+	return i, nil
+}
+
+// RegisterSerialized -- Register with a serialized public key and pop
+func (r *Registry) RegisterSerialized(pub []byte, pop []byte) (uint32, error) {
+	var p bls.PublicKey
+	if err := p.Deserialize(pub); err != nil {
+		return 0, ErrInvalidKey
+	}
+	var s bls.Sign
+	if err := s.Deserialize(pop); err != nil {
+		return 0, ErrInvalidPop
+	}
+	return r.Register(&p, &s)
+}
+
+// Len -- number of admitted keys, indices are 0 to Len() - 1
+func (r *Registry) Len() int {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return len(r.keys)
+}
+
+// Index returns the index of pub and whether it was admitted
+func (r *Registry) Index(pub *bls.PublicKey) (uint32, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	i, ok := r.index[string(pub.Serialize())]
+	return i, ok
+}
+
+// PublicKey returns the key at index
+func (r *Registry) PublicKey(index uint32) (*bls.PublicKey, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if int64(index) >= int64(len(r.keys)) {
+		return nil, fmt.Errorf("err registry:unknown index %d", index)
+	}
+	pub := r.keys[index]
+	return &pub, nil
+}
+
+// AggregateByIndices returns the sum of the keys at indices, which must be distinct
+func (r *Registry) AggregateByIndices(indices []uint32) (bls.PublicKey, error) {
+	var agg bls.PublicKey
+	if len(indices) == 0 {
+		return agg, fmt.Errorf("err registry:no indices")
+	}
+	seen := make(map[uint32]bool, len(indices))
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for _, i := range indices {
+		if int64(i) >= int64(len(r.keys)) {
+			return agg, fmt.Errorf("err registry:unknown index %d", i)
+		}
+		if seen[i] {
+			return agg, fmt.Errorf("err registry:duplicate index %d", i)
+		}
+		seen[i] = true
+		agg.Add(&r.keys[i])
+	}
+	return agg, nil
+}
+
+// FastAggregateVerify verifies sig, the aggregate of the signatures of msg by the keys at indices
+// It is false if indices are empty, unknown or repeated
+func (r *Registry) FastAggregateVerify(indices []uint32, msg []byte, sig *bls.Sign) bool {
+	if len(msg) == 0 {
+		return false
+	}
+	agg, err := r.AggregateByIndices(indices)
+	if err != nil {
+		return false
+	}
+	return sig.Verify(&agg, msg)
+}
@@ -0,0 +1,90 @@
+package bls
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+)
+
+// ---------------- Public Key Encryption --------------------
+// Seal and Open implement ECIES style hybrid encryption to a PublicKey on top of DHKeyExchange.
+// The sender picks an ephemeral key e and derives an AES-256-GCM key from the shared point:
+//
+//	shared = DHKeyExchange(e, recipient)
+//	key    = HKDF-SHA256(ikm = shared, salt = "", info = SealInfo | E | recipient)
+//	ciphertext = SealVersion | E | AES-256-GCM(key, nonce = 0, plaintext, aad = SealVersion | E | aad)
+//
+// where E is the serialized ephemeral public key. Each key encrypts a single message, so the
+// zero nonce is safe.
+
+// SealVersion -- the version byte of Seal ciphertexts
+const SealVersion byte = 1
+
+// SealInfo -- the HKDF info prefix of Seal
+const SealInfo = "BLS-SEAL-V1"
+
+// Seal encrypts plaintext to recipient, authenticating aad which must be given to Open as well
+func Seal(recipient *PublicKey, plaintext []byte, aad []byte) ([]byte, error) {
+	if recipient.v.IsZero() {
+		return nil, fmt.Errorf("err Seal:zero public key")
+	}
+	eph := NewSecretKey()
+	ephPub := eph.GetPublicKey()
+	shared := DHKeyExchange(&eph, recipient)
+	eph.v.Clear()
+
+	header := append([]byte{SealVersion}, ephPub.Serialize()...)
+	aead, err := sealAEAD(&shared, ephPub, recipient)
+	if err != nil {
+		return nil, err
+	}
+	fullAAD := append(append([]byte{}, header...), aad...)
+	out := make([]byte, len(header), len(header)+len(plaintext)+aead.Overhead())
+	copy(out, header)
+	return aead.Seal(out, make([]byte, aead.NonceSize()), plaintext, fullAAD), nil
+}
+
+// Open decrypts a ciphertext produced by Seal for the public key of sec
+func Open(sec *SecretKey, ciphertext []byte, aad []byte) ([]byte, error) {
+	if len(ciphertext) == 0 {
+		return nil, fmt.Errorf("err Open:empty ciphertext")
+	}
+	if ciphertext[0] != SealVersion {
+		return nil, fmt.Errorf("err Open:unknown version %d", ciphertext[0])
+	}
+	g2Size := len(sec.GetPublicKey().Serialize())
+	if len(ciphertext) < 1+g2Size {
+		return nil, fmt.Errorf("err Open:short ciphertext")
+	}
+	header := ciphertext[:1+g2Size]
+	var ephPub PublicKey
+	if err := ephPub.Deserialize(header[1:]); err != nil {
+		return nil, fmt.Errorf("err Open:bad ephemeral key")
+	}
+	if ephPub.v.IsZero() || !ephPub.v.IsValidOrder() {
+		return nil, fmt.Errorf("err Open:bad ephemeral key")
+	}
+	shared := DHKeyExchange(sec, &ephPub)
+	aead, err := sealAEAD(&shared, &ephPub, sec.GetPublicKey())
+	if err != nil {
+		return nil, err
+	}
+	fullAAD := append(append([]byte{}, header...), aad...)
+	plaintext, err := aead.Open(nil, make([]byte, aead.NonceSize()), ciphertext[len(header):], fullAAD)
+	if err != nil {
+		return nil, fmt.Errorf("err Open:decryption failed")
+	}
+	return plaintext, nil
+}
+
+// sealAEAD returns the AES-256-GCM instance keyed from the shared point
+func sealAEAD(shared *PublicKey, ephPub *PublicKey, recipient *PublicKey) (cipher.AEAD, error) {
+	info := append([]byte(SealInfo), ephPub.Serialize()...)
+	info = append(info, recipient.Serialize()...)
+	key := hkdfExpand(hkdfExtract(nil, shared.Serialize()), info, 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,125 @@
+// Package sortition implements stake weighted cryptographic sortition in the style of Algorand
+// using the bls VRF (SecretKey.Sign over the sortition input).
+//
+// A node with weight w out of a total weight W is treated as w sub-users, each selected with
+// probability p = CommitteeSize / W. The number of seats it wins is the j for which the VRF
+// output, read as a fraction in [0, 1), falls into
+//
+//	[ sum_{k<j} B(k; w, p), sum_{k<=j} B(k; w, p) )
+//
+// where B is the binomial distribution. All arithmetic is done with math/big floats so every
+// verifier computes the same seat count on every platform, and the VRF output does not depend on
+// SetETHserialization.
+package sortition
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+// precision -- bits of precision used for the binomial distribution
+const precision = 256
+
+// ErrInvalidProof is returned by Verify when the VRF proof does not verify
+var ErrInvalidProof = errors.New("err sortition:invalid proof")
+
+// Params -- the committee parameters shared by all participants
+type Params struct {
+	// CommitteeSize -- the expected number of seats
+	CommitteeSize uint64
+	// TotalWeight -- the sum of the weights of all participants
+	TotalWeight uint64
+}
+
+// Validate checks that the parameters define a selection probability in (0, 1]
+func (params Params) Validate() error {
+	if params.TotalWeight == 0 {
+		return fmt.Errorf("err sortition:zero total weight")
+	}
+	if params.CommitteeSize == 0 || params.CommitteeSize > params.TotalWeight {
+		return fmt.Errorf("err sortition:committee size %d must be in [1, %d]", params.CommitteeSize, params.TotalWeight)
+	}
+	return nil
+}
+
+// Select runs the sortition of sec on alpha (typically seed | round | role) with the given weight
+// It returns the proof to publish along with the number of seats won
+func Select(sec *bls.SecretKey, alpha []byte, weight uint64, params Params) (proof bls.Sign, seats uint64, err error) {
+	proof, output := bls.VRFProve(sec, alpha)
+	seats, err = params.Seats(output, weight)
+	return proof, seats, err
+}
+
+// Verify checks proof for pub and alpha and recomputes the number of seats won with the given weight
+func Verify(pub *bls.PublicKey, alpha []byte, proof *bls.Sign, weight uint64, params Params) (uint64, error) {
+	output, ok := bls.VRFVerify(pub, alpha, proof)
+	if !ok {
+		return 0, ErrInvalidProof
+	}
+	return params.Seats(output, weight)
+}
+
+// Seats returns the number of seats won by a VRF output with the given weight
+func (params Params) Seats(output [bls.VRFOutputSize]byte, weight uint64) (uint64, error) {
+	if err := params.Validate(); err != nil {
+		return 0, err
+	}
+	if weight > params.TotalWeight {
+		return 0, fmt.Errorf("err sortition:weight %d exceeds total weight %d", weight, params.TotalWeight)
+	}
+	if weight == 0 {
+		return 0, nil
+	}
+	if params.CommitteeSize == params.TotalWeight {
+		// p = 1: every sub-user is selected
+		return weight, nil
+	}
+
+	// ratio = output / 2^256
+	ratio := newFloat().SetInt(new(big.Int).SetBytes(output[:]))
+	ratio.SetMantExp(ratio, -8*len(output))
+
+	// p and q = 1 - p
+	p := newFloat().Quo(newFloat().SetUint64(params.CommitteeSize), newFloat().SetUint64(params.TotalWeight))
+	q := newFloat().Sub(newFloat().SetUint64(1), p)
+	pq := newFloat().Quo(p, q)
+
+	// b = B(0; w, p) = q^w
+	b := pow(q, weight)
+	cum := newFloat().Set(b)
+	t := newFloat()
+	for k := uint64(0); k < weight; k++ {
+		if ratio.Cmp(cum) < 0 {
+			return k, nil
+		}
+		// B(k + 1) = B(k) * (w - k) / (k + 1) * p / q
+		t.SetUint64(weight - k)
+		b.Mul(b, t)
+		t.SetUint64(k + 1)
+		b.Quo(b, t)
+		b.Mul(b, pq)
+		cum.Add(cum, b)
+	}
+	return weight, nil
+}
+
+func newFloat() *big.Float {
+	return new(big.Float).SetPrec(precision)
+}
+
+// pow returns x^n by square and multiply
+func pow(x *big.Float, n uint64) *big.Float {
+	result := newFloat().SetUint64(1)
+	base := newFloat().Set(x)
+	for n > 0 {
+		if n&1 == 1 {
+			result.Mul(result, base)
+		}
+		base.Mul(base, base)
+		n >>= 1
+	}
+	return result
+}
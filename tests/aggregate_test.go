@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+func TestAggregateVerify(t *testing.T) {
+	const n = 5
+	pubVec := make([]bls.PublicKey, n)
+	messages := make([][]byte, n)
+	var agg bls.Sign
+	for i := 0; i < n; i++ {
+		var sec bls.SecretKey
+		sec.SetByCSPRNG()
+		pubVec[i] = *sec.GetPublicKey()
+		messages[i] = []byte(fmt.Sprintf("message %d", i))
+		sig := sec.Sign(messages[i])
+		if i == 0 {
+			agg = *sig
+		} else {
+			agg.Add(sig)
+		}
+	}
+	if !agg.AggregateVerify(pubVec, messages) {
+		t.Fatal("aggregated signature does not verify")
+	}
+	messages[0], messages[1] = messages[1], messages[0]
+	if agg.AggregateVerify(pubVec, messages) {
+		t.Error("aggregated signature verifies with swapped messages")
+	}
+	messages[0], messages[1] = messages[1], messages[0]
+	if agg.AggregateVerify(pubVec[:n-1], messages[:n-1]) {
+		t.Error("aggregated signature verifies without a signer")
+	}
+	if agg.AggregateVerify(pubVec, messages[:n-1]) {
+		t.Error("expected failure for mismatched lengths")
+	}
+	if agg.AggregateVerify(nil, nil) {
+		t.Error("expected failure for no signers")
+	}
+
+	var zeroPub bls.PublicKey
+	var zeroSig bls.Sign
+	if zeroPub.IsValid() || zeroSig.IsValid() {
+		t.Error("zero public key or signature is valid")
+	}
+	if zeroSig.AggregateVerify([]bls.PublicKey{zeroPub}, messages[:1]) {
+		t.Error("zero signature verifies with a zero public key")
+	}
+	if agg.AggregateVerify(append(pubVec, zeroPub), append(messages, []byte("zero"))) {
+		t.Error("aggregated signature verifies with a zero public key")
+	}
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+func TestSub(t *testing.T) {
+	var a, b bls.SecretKey
+	a.SetByCSPRNG()
+	b.SetByCSPRNG()
+	msg := []byte("sub message")
+	sec, pub, sig := a, *a.GetPublicKey(), *a.Sign(msg)
+	sec.Add(&b)
+	pub.Add(b.GetPublicKey())
+	sig.Add(b.Sign(msg))
+	sec.Sub(&b)
+	pub.Sub(b.GetPublicKey())
+	sig.Sub(b.Sign(msg))
+	if !sec.IsEqual(&a) || !pub.IsEqual(a.GetPublicKey()) || !sig.IsEqual(a.Sign(msg)) {
+		t.Error("Sub does not undo Add")
+	}
+	sec.Sub(&a)
+	if !bls.CastFromSecretKey(&sec).IsZero() {
+		t.Error("sec - sec is not zero")
+	}
+	pub.Sub(&pub)
+	if !bls.CastFromPublicKey(&pub).IsZero() {
+		t.Error("pub - pub is not zero")
+	}
+	sig.Sub(&sig)
+	if !bls.CastFromSign(&sig).IsZero() {
+		t.Error("sig - sig is not zero")
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	const n = 20
+	secVec, pubVec := makeCommittee(n)
+	msg := []byte("vote")
+	sigVec := make([]bls.Sign, n)
+	for i := range secVec {
+		sigVec[i] = *secVec[i].Sign(msg)
+	}
+	a := bls.NewAggregator()
+	if a.Snapshot().Verify(msg) {
+		t.Error("empty aggregate verifies")
+	}
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	added := 0
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pubVec {
+				if a.Add(&pubVec[i], &sigVec[i]) {
+					lock.Lock()
+					added++
+					lock.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if added != n || a.Len() != n {
+		t.Fatalf("added %d signers, aggregator has %d, expected %d", added, a.Len(), n)
+	}
+	s := a.Snapshot()
+	if !s.Verify(msg) || len(s.Signers) != n {
+		t.Fatal("aggregate does not verify")
+	}
+
+	// a bad contribution is removed
+	var bad bls.SecretKey
+	bad.SetByCSPRNG()
+	if !a.Add(bad.GetPublicKey(), bad.Sign([]byte("other"))) {
+		t.Fatal("new signer not added")
+	}
+	if a.Snapshot().Verify(msg) {
+		t.Error("aggregate with a bad signature verifies")
+	}
+	if !a.Remove(bad.GetPublicKey()) || a.Remove(bad.GetPublicKey()) || a.Has(bad.GetPublicKey()) {
+		t.Error("bad remove")
+	}
+	if !a.Snapshot().Verify(msg) {
+		t.Error("aggregate does not verify after removal")
+	}
+
+	// removing signers matches aggregating the others
+	for i := 0; i < n; i += 3 {
+		a.Remove(&pubVec[i])
+	}
+	s = a.Snapshot()
+	var expected bls.Sign
+	for i := range sigVec {
+		if i%3 != 0 {
+			expected.Add(&sigVec[i])
+			if !a.Has(&pubVec[i]) {
+				t.Errorf("signer %d missing", i)
+			}
+		}
+	}
+	if !s.Signature.IsEqual(&expected) || !s.Verify(msg) || len(s.Signers) != a.Len() {
+		t.Error("bad aggregate after removals")
+	}
+	for i := 0; i < n; i += 3 {
+		if !a.Add(&pubVec[i], &sigVec[i]) {
+			t.Errorf("signer %d not added again", i)
+		}
+	}
+	if !a.Snapshot().Verify(msg) || a.Len() != n {
+		t.Error("bad aggregate after adding removed signers")
+	}
+	if !s.Verify(msg) || len(s.Signers) == n {
+		t.Error("snapshot changed with the aggregator")
+	}
+}
+
+func TestAggregatorZeroValue(t *testing.T) {
+	secVec, pubVec := makeCommittee(2)
+	msg := []byte("vote")
+	var a bls.Aggregator
+	if a.Has(&pubVec[0]) || a.Remove(&pubVec[0]) || a.Len() != 0 {
+		t.Fatal("zero aggregator is not empty")
+	}
+	for i := range secVec {
+		if !a.Add(&pubVec[i], secVec[i].Sign(msg)) {
+			t.Fatalf("signer %d not added", i)
+		}
+	}
+	if a.Len() != 2 || !a.Snapshot().Verify(msg) {
+		t.Error("aggregate of the zero aggregator does not verify")
+	}
+}
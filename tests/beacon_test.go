@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/beacon"
+)
+
+type beaconCommittee struct {
+	idVec  []bls.ID
+	secVec []bls.SecretKey
+	pubVec []bls.PublicKey
+}
+
+func newBeaconCommittee(t *testing.T, mode beacon.Mode, n int, threshold int) (*beacon.Chain, *beaconCommittee) {
+	sec := bls.NewSecretKey()
+	msk := sec.GetMasterSecretKey(threshold)
+	mpk := bls.GetMasterPublicKey(msk)
+	c := &beaconCommittee{
+		idVec:  make([]bls.ID, n),
+		secVec: make([]bls.SecretKey, n),
+		pubVec: make([]bls.PublicKey, n),
+	}
+	for i := 0; i < n; i++ {
+		if err := c.idVec[i].SetLittleEndian([]byte{byte(i + 1)}); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.secVec[i].Set(msk, &c.idVec[i]); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.pubVec[i].Set(mpk, &c.idVec[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	chain := &beacon.Chain{Mode: mode, GroupKey: *sec.GetPublicKey(), Threshold: threshold, Genesis: []byte("genesis seed")}
+	return chain, c
+}
+
+func (c *beaconCommittee) partials(t *testing.T, chain *beacon.Chain, members []int, round uint64, prevSig []byte) []beacon.Partial {
+	partials := make([]beacon.Partial, 0, len(members))
+	for _, i := range members {
+		p := chain.PartialSign(&c.secVec[i], &c.idVec[i], round, prevSig)
+		if !chain.VerifyPartial(&c.pubVec[i], &p, prevSig) {
+			t.Fatalf("partial of member %d does not verify", i)
+		}
+		partials = append(partials, p)
+	}
+	return partials
+}
+
+func TestBeaconChained(t *testing.T) {
+	chain, committee := newBeaconCommittee(t, beacon.Chained, 5, 3)
+	prev := &beacon.Beacon{Round: 0, Signature: chain.Genesis}
+	for round := uint64(1); round <= 3; round++ {
+		b1, err := chain.Combine(round, prev.Signature, committee.partials(t, chain, []int{0, 1, 2}, round, prev.Signature))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b2, err := chain.Combine(round, prev.Signature, committee.partials(t, chain, []int{4, 2, 3}, round, prev.Signature))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b1.Signature) != string(b2.Signature) || b1.Randomness() != b2.Randomness() {
+			t.Errorf("round %d: different signer sets gave different beacons", round)
+		}
+		if !chain.Verify(b1) {
+			t.Errorf("round %d does not verify", round)
+		}
+		if !chain.VerifyNext(prev, b1) {
+			t.Errorf("round %d does not follow round %d", round, prev.Round)
+		}
+		prev = b1
+	}
+
+	// tampering with the chain is detected
+	bad := *prev
+	bad.PreviousSignature = chain.Genesis
+	if chain.Verify(&bad) {
+		t.Error("beacon with a wrong previous signature verifies")
+	}
+	bad = *prev
+	bad.Round++
+	if chain.Verify(&bad) {
+		t.Error("beacon with a wrong round verifies")
+	}
+	first := &beacon.Beacon{Round: 1, PreviousSignature: []byte("other seed")}
+	if chain.Verify(first) {
+		t.Error("round 1 with a wrong genesis verifies")
+	}
+}
+
+func TestBeaconUnchained(t *testing.T) {
+	chain, committee := newBeaconCommittee(t, beacon.Unchained, 4, 2)
+	// unchained rounds can be produced in any order
+	b7, err := chain.Combine(7, nil, committee.partials(t, chain, []int{1, 3}, 7, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b6, err := chain.Combine(6, nil, committee.partials(t, chain, []int{0, 2}, 6, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b7.PreviousSignature != nil {
+		t.Error("unchained beacon has a previous signature")
+	}
+	if !chain.Verify(b6) || !chain.Verify(b7) || !chain.VerifyNext(b6, b7) {
+		t.Error("unchained beacons do not verify")
+	}
+	if b6.Randomness() == b7.Randomness() {
+		t.Error("rounds have the same randomness")
+	}
+}
+
+func TestBeaconCombineErrors(t *testing.T) {
+	chain, committee := newBeaconCommittee(t, beacon.Chained, 5, 3)
+	prevSig := chain.Genesis
+
+	partials := committee.partials(t, chain, []int{0, 1}, 1, prevSig)
+	if _, err := chain.Combine(1, prevSig, partials); err == nil {
+		t.Error("expected error for too few partials")
+	}
+	partials = committee.partials(t, chain, []int{0, 1, 1}, 1, prevSig)
+	if _, err := chain.Combine(1, prevSig, partials); err == nil {
+		t.Error("expected error for duplicate partials")
+	}
+	partials = committee.partials(t, chain, []int{0, 1, 2}, 2, prevSig)
+	if _, err := chain.Combine(1, prevSig, partials); err == nil {
+		t.Error("expected error for partials of another round")
+	}
+	partials = committee.partials(t, chain, []int{0, 1, 2}, 1, prevSig)
+	partials[1].Signature = *committee.secVec[1].Sign([]byte("something else"))
+	if chain.VerifyPartial(&committee.pubVec[1], &partials[1], prevSig) {
+		t.Error("bad partial verifies")
+	}
+	if _, err := chain.Combine(1, prevSig, partials); err == nil {
+		t.Error("expected error for a bad partial")
+	}
+}
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+func TestBlindSign(t *testing.T) {
+	sec := bls.NewSecretKey()
+	pub := sec.GetPublicKey()
+	m := []byte("blind token 1")
+
+	blinded, factor := bls.Blind(m)
+	blindSig := sec.SignBlinded(blinded)
+	sig := bls.Unblind(blindSig, &factor)
+	if !sig.Verify(pub, m) {
+		t.Error("unblinded signature does not verify")
+	}
+	// bls signatures are unique so the result is the ordinary signature on m
+	if !sig.IsEqual(sec.Sign(m)) {
+		t.Error("unblinded signature differs from the direct signature")
+	}
+	if sig.Verify(pub, []byte("blind token 2")) {
+		t.Error("unblinded signature verifies for another message")
+	}
+}
+
+func TestBlindUnlinkable(t *testing.T) {
+	sec := bls.NewSecretKey()
+	pub := sec.GetPublicKey()
+	m := []byte("blind token")
+
+	var h bls.G1
+	if err := h.HashAndMapTo(m); err != nil {
+		t.Fatal(err)
+	}
+	blinded1, factor1 := bls.Blind(m)
+	blinded2, factor2 := bls.Blind(m)
+	blindSig1 := sec.SignBlinded(blinded1)
+	blindSig2 := sec.SignBlinded(blinded2)
+	sig1 := bls.Unblind(blindSig1, &factor1)
+	sig2 := bls.Unblind(blindSig2, &factor2)
+
+	// the issuer sees fresh random points in each session, unrelated to H(m) and to the final signature
+	if blinded1.IsEqual(blinded2) || blinded1.IsEqual(&h) || blinded2.IsEqual(&h) {
+		t.Error("blinded points reveal the message")
+	}
+	if blindSig1.IsEqual(blindSig2) || blindSig1.IsEqual(sig1) || blindSig2.IsEqual(sig2) {
+		t.Error("issuer view matches the final signature")
+	}
+	if blindSig1.Verify(pub, m) || blindSig2.Verify(pub, m) {
+		t.Error("blinded signature verifies without unblinding")
+	}
+	// both sessions end in the same unique signature, so the signature cannot tell them apart
+	if !sig1.IsEqual(sig2) {
+		t.Error("unblinded signatures differ")
+	}
+	// unblinding with the wrong factor fails
+	if bls.Unblind(blindSig1, &factor2).Verify(pub, m) {
+		t.Error("unblinding with a wrong factor verifies")
+	}
+}
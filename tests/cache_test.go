@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/verifier"
+)
+
+func TestCacheVerify(t *testing.T) {
+	var sec bls.SecretKey
+	sec.SetByCSPRNG()
+	pub := sec.GetPublicKey()
+	msg := []byte("gossip")
+	sig := sec.Sign(msg)
+	c := verifier.NewCache(2)
+	for i := 0; i < 3; i++ {
+		if !c.Verify(sig, pub, msg) {
+			t.Fatal("valid signature rejected")
+		}
+	}
+	if s := c.Stats(); s.Hits != 2 || s.Misses != 1 || s.Len != 1 || s.HitRate() < 0.66 {
+		t.Errorf("bad stats %+v", s)
+	}
+	// failures are not cached
+	for i := 0; i < 2; i++ {
+		if c.Verify(sig, pub, []byte("other")) {
+			t.Fatal("invalid signature accepted")
+		}
+	}
+	if s := c.Stats(); s.Hits != 2 || s.Len != 1 {
+		t.Errorf("bad stats after failures %+v", s)
+	}
+	// a different signature of the same message under the same key is another entry
+	sig2 := *sig
+	sig2.Add(sig)
+	if c.Verify(&sig2, pub, msg) {
+		t.Fatal("invalid signature accepted")
+	}
+
+	// LRU eviction
+	first := verifier.NewSingleJob(pub, msg, sig)
+	for i := 0; i < 2; i++ {
+		m := []byte(fmt.Sprintf("message %d", i))
+		c.Verify(sec.Sign(m), pub, m)
+	}
+	if c.Contains(&first) {
+		t.Error("least recently used job not evicted")
+	}
+	if s := c.Stats(); s.Evictions != 1 || s.Len != 2 || s.Capacity != 2 {
+		t.Errorf("bad stats after eviction %+v", s)
+	}
+	c.Purge()
+	if s := c.Stats(); s.Len != 0 {
+		t.Errorf("bad stats after purge %+v", s)
+	}
+
+	// malformed inputs are rejected before the cache
+	var zero bls.PublicKey
+	var zeroSig bls.Sign
+	before := c.Stats()
+	if c.Verify(sig, pub, nil) {
+		t.Error("empty message accepted")
+	}
+	for i := 0; i < 2; i++ {
+		if c.Verify(&zeroSig, &zero, msg) {
+			t.Error("zero public key accepted")
+		}
+	}
+	if s := c.Stats(); s != before {
+		t.Errorf("bad stats after malformed inputs %+v", s)
+	}
+}
+
+func TestCacheSerializationMode(t *testing.T) {
+	var sec bls.SecretKey
+	sec.SetByCSPRNG()
+	msg := []byte("gossip")
+	job := verifier.NewSingleJob(sec.GetPublicKey(), msg, sec.Sign(msg))
+	c := verifier.NewCache(1)
+	c.Add(&job)
+	bls.SetETHserialization(true)
+	defer bls.SetETHserialization(false)
+	if !c.Contains(&job) {
+		t.Error("cache key depends on the serialization mode")
+	}
+}
+
+func TestCacheVerifier(t *testing.T) {
+	jobs, expected := verifierJobs(t, 24)
+	c := verifier.NewCache(100)
+	v := verifier.New(context.Background(), verifier.Config{Workers: 2, Cache: c})
+	defer v.Close()
+	nValid := 0
+	for round := 0; round < 3; round++ {
+		for i := range jobs {
+			valid, err := v.Verify(context.Background(), jobs[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if valid != expected[i] {
+				t.Errorf("round %d job %d: valid %v, expected %v", round, i, valid, expected[i])
+			}
+			if round == 0 && valid {
+				nValid++
+			}
+		}
+	}
+	s := c.Stats()
+	if s.Len != nValid || s.Hits != uint64(2*nValid) {
+		t.Errorf("bad stats %+v for %d valid jobs", s, nValid)
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	var sec bls.SecretKey
+	sec.SetByCSPRNG()
+	pub := sec.GetPublicKey()
+	sigs := make([]*bls.Sign, 8)
+	for i := range sigs {
+		sigs[i] = sec.Sign([]byte{byte(i + 1)})
+	}
+	c := verifier.NewCache(4)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				j := (g + i) % len(sigs)
+				if !c.Verify(sigs[j], pub, []byte{byte(j + 1)}) {
+					t.Errorf("signature %d rejected", j)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if s := c.Stats(); s.Hits+s.Misses != 160 || s.Len > 4 {
+		t.Errorf("bad stats %+v", s)
+	}
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+func makeCommittee(n int) ([]bls.SecretKey, []bls.PublicKey) {
+	secVec := make([]bls.SecretKey, n)
+	pubVec := make([]bls.PublicKey, n)
+	for i := range secVec {
+		secVec[i].SetByCSPRNG()
+		pubVec[i] = *secVec[i].GetPublicKey()
+	}
+	return secVec, pubVec
+}
+
+func TestAggregateCertificate(t *testing.T) {
+	const n = 11
+	secVec, pubVec := makeCommittee(n)
+	msg := []byte("block 42")
+
+	c1 := bls.NewAggregateCertificate(n)
+	c2 := bls.NewAggregateCertificate(n)
+	for _, i := range []int{0, 3, 10} {
+		if err := c1.Add(i, secVec[i].Sign(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, i := range []int{1, 8} {
+		if err := c2.Add(i, secVec[i].Sign(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c1.Add(3, secVec[3].Sign(msg)); err == nil {
+		t.Error("expected error for a repeated signer")
+	}
+	if err := c1.Add(n, secVec[0].Sign(msg)); err == nil {
+		t.Error("expected error for an index out of the committee")
+	}
+	if !c1.Verify(pubVec, msg) || !c2.Verify(pubVec, msg) {
+		t.Fatal("certificate does not verify")
+	}
+	if err := c1.Merge(c2); err != nil {
+		t.Fatal(err)
+	}
+	if c1.Count() != 5 || c1.Size() != n {
+		t.Errorf("bad count %d or size %d", c1.Count(), c1.Size())
+	}
+	signers := c1.Signers()
+	for k, i := range []int{0, 1, 3, 8, 10} {
+		if signers[k] != i {
+			t.Fatalf("bad signers %v", signers)
+		}
+	}
+	if !c1.Verify(pubVec, msg) {
+		t.Error("merged certificate does not verify")
+	}
+	if err := c1.Merge(c2); err == nil {
+		t.Error("expected error for overlapping certificates")
+	}
+	if err := c1.Merge(bls.NewAggregateCertificate(n + 1)); err == nil {
+		t.Error("expected error for another committee size")
+	}
+	if c1.Verify(pubVec, []byte("other")) || c1.Verify(pubVec[:n-1], msg) {
+		t.Error("certificate verifies with another message or committee")
+	}
+	swapped := append([]bls.PublicKey{}, pubVec...)
+	swapped[0], swapped[2] = swapped[2], swapped[0]
+	if c1.Verify(swapped, msg) {
+		t.Error("certificate verifies with another order of the committee")
+	}
+	if bls.NewAggregateCertificate(n).Verify(pubVec, msg) {
+		t.Error("certificate without signers verifies")
+	}
+}
+
+func TestAggregateCertificateEncoding(t *testing.T) {
+	msg := []byte("block 43")
+	for _, n := range []int{1, 8, 9, 200} {
+		secVec, pubVec := makeCommittee(n)
+		c := bls.NewAggregateCertificate(n)
+		for i := 0; i < n; i += 3 {
+			if err := c.Add(i, secVec[i].Sign(msg)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		buf := c.Serialize()
+		var c2 bls.AggregateCertificate
+		if err := c2.Deserialize(buf); err != nil {
+			t.Fatalf("n=%d: %v", n, err)
+		}
+		if !bytes.Equal(c2.Serialize(), buf) || !c2.Verify(pubVec, msg) || c2.Count() != c.Count() {
+			t.Errorf("n=%d: bad round trip", n)
+		}
+		if err := c2.Deserialize(buf[:len(buf)-1]); err == nil {
+			t.Errorf("n=%d: expected error for a short buffer", n)
+		}
+		if err := c2.Deserialize(append(append([]byte{}, buf...), 0)); err == nil {
+			t.Errorf("n=%d: expected error for trailing bytes", n)
+		}
+		if n%8 != 0 {
+			bad := append([]byte{}, buf...)
+			sizeLen := 1
+			if n >= 128 {
+				sizeLen = 2
+			}
+			bad[sizeLen+(n-1)/8] |= 0x80
+			if err := c2.Deserialize(bad); err == nil {
+				t.Errorf("n=%d: expected error for a bit beyond the committee", n)
+			}
+		}
+	}
+	// non minimal size
+	c := bls.NewAggregateCertificate(1)
+	buf := c.Serialize()
+	var c2 bls.AggregateCertificate
+	if err := c2.Deserialize(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := c2.Deserialize(append([]byte{0x81, 0x00}, buf[1:]...)); err == nil {
+		t.Error("expected error for a non minimal committee size")
+	}
+	if err := c2.Deserialize([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}); err == nil {
+		t.Error("expected error for a huge committee size")
+	}
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+// test vectors from https://eips.ethereum.org/EIPS/eip-2333#test-cases
+var eip2333Vectors = []struct {
+	seed       string
+	masterSK   string
+	childIndex uint32
+	childSK    string
+}{
+	{
+		seed:       "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		masterSK:   "6083874454709270928345386274498605044986640685124978867557563392430687146096",
+		childIndex: 0,
+		childSK:    "20397789859736650942317412262472558107875392172444076792671091975210932703118",
+	},
+	{
+		seed:       "3141592653589793238462643383279502884197169399375105820974944592",
+		masterSK:   "29757020647961307431480504535336562678282505419141012933316116377660817309383",
+		childIndex: 3141592653,
+		childSK:    "25457201688850691947727629385191704516744796114925897962676248250929345014287",
+	},
+	{
+		seed:       "0099FF991111002299DD7744EE3355BBDD8844115566CC55663355668888CC00",
+		masterSK:   "27580842291869792442942448775674722299803720648445448686099262467207037398656",
+		childIndex: 4294967295,
+		childSK:    "29358610794459428860402234341874281240803786294062035874021252734817515685787",
+	},
+	{
+		seed:       "d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3",
+		masterSK:   "19022158461524446591288038168518313374041767046816487870552872741050760015818",
+		childIndex: 42,
+		childSK:    "31372231650479070279774297061823572166496564838472787488249775572789064611981",
+	},
+}
+
+func TestEIP2333Vectors(t *testing.T) {
+	for i, v := range eip2333Vectors {
+		seed, err := hex.DecodeString(v.seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		master, err := bls.DeriveMasterSK(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if master.GetDecString() != v.masterSK {
+			t.Errorf("vector %d: bad master SK\n%s\n%s", i, master.GetDecString(), v.masterSK)
+		}
+		child := bls.DeriveChildSK(&master, v.childIndex)
+		if child.GetDecString() != v.childSK {
+			t.Errorf("vector %d: bad child SK\n%s\n%s", i, child.GetDecString(), v.childSK)
+		}
+	}
+}
+
+func TestEIP2333ShortSeed(t *testing.T) {
+	if _, err := bls.DeriveMasterSK(make([]byte, 31)); err == nil {
+		t.Error("expected error for a 31 byte seed")
+	}
+}
+
+func TestEIP2334Path(t *testing.T) {
+	indices, err := bls.ParsePath("m/12381/3600/0/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []uint32{12381, 3600, 0, 0}
+	if len(indices) != len(expected) {
+		t.Fatalf("bad indices %v", indices)
+	}
+	for i := range expected {
+		if indices[i] != expected[i] {
+			t.Errorf("bad index %d: %d", i, indices[i])
+		}
+	}
+	if indices, err := bls.ParsePath("m"); err != nil || len(indices) != 0 {
+		t.Errorf("bad master path: %v %v", indices, err)
+	}
+	for _, bad := range []string{"", "x/1", "m/", "m/-1", "m/4294967296", "m/1//2", "m/a"} {
+		if _, err := bls.ParsePath(bad); err == nil {
+			t.Errorf("expected error for path %q", bad)
+		}
+	}
+
+	for _, path := range []string{"m", "m/12381", "m/12381/3600/0/0/0"} {
+		if err := bls.ValidatePath(path); err != nil {
+			t.Errorf("path %q: %v", path, err)
+		}
+	}
+	for _, bad := range []string{"m/0", "m/44/60/0/0", "m/a"} {
+		if err := bls.ValidatePath(bad); err == nil {
+			t.Errorf("expected error for path %q", bad)
+		}
+	}
+
+	seed, err := hex.DecodeString(eip2333Vectors[0].seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec, err := bls.DeriveSKFromPath(seed, "m/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sec.GetDecString() != eip2333Vectors[0].childSK {
+		t.Errorf("bad path derivation\n%s\n%s", sec.GetDecString(), eip2333Vectors[0].childSK)
+	}
+}
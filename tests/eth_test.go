@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/eth"
+)
+
+func TestETHSignVerify(t *testing.T) {
+	msgs := [][]byte{[]byte("abc"), []byte("def"), nil}
+	secs := make([]eth.SecretKey, len(msgs))
+	pubs := make([]eth.PublicKey, len(msgs))
+	sigs := make([]eth.Signature, len(msgs))
+	for i := range secs {
+		secs[i].SetByCSPRNG()
+		pubs[i] = *secs[i].PublicKey()
+		sigs[i] = *secs[i].Sign(msgs[i])
+		if !eth.Verify(&pubs[i], msgs[i], &sigs[i]) {
+			t.Fatalf("signature %d does not verify", i)
+		}
+		if eth.Verify(&pubs[i], []byte("xyz"), &sigs[i]) {
+			t.Errorf("signature %d verifies another message", i)
+		}
+	}
+	agg, err := eth.Aggregate(sigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !eth.AggregateVerify(pubs, msgs, agg) {
+		t.Error("aggregate signature does not verify")
+	}
+	if eth.AggregateVerify(pubs[:2], msgs[:2], agg) {
+		t.Error("aggregate signature verifies a subset")
+	}
+	if _, err := eth.Aggregate(nil); err != eth.ErrNoInputs {
+		t.Errorf("aggregated nothing: %v", err)
+	}
+
+	for i := range sigs {
+		sigs[i] = *secs[i].Sign(msgs[0])
+	}
+	if agg, err = eth.Aggregate(sigs); err != nil {
+		t.Fatal(err)
+	}
+	if !eth.FastAggregateVerify(pubs, msgs[0], agg) || !eth.EthFastAggregateVerify(pubs, msgs[0], agg) {
+		t.Error("fast aggregate signature does not verify")
+	}
+	if eth.FastAggregateVerify(pubs[1:], msgs[0], agg) {
+		t.Error("fast aggregate signature verifies a subset")
+	}
+}
+
+func TestETHSecretKey(t *testing.T) {
+	sec, err := bls.KeyGen(bytes.Repeat([]byte{7}, 32), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esec := eth.FromSecretKey(&sec)
+	buf := esec.Serialize()
+	if len(buf) != eth.SecretKeySize {
+		t.Fatalf("bad secret key size %d", len(buf))
+	}
+	x, ok := new(big.Int).SetString(bls.CastFromSecretKey(&sec).GetString(10), 10)
+	if !ok || x.Cmp(new(big.Int).SetBytes(buf)) != 0 {
+		t.Error("secret key is not the big-endian scalar")
+	}
+	var esec2 eth.SecretKey
+	if err := esec2.Deserialize(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !esec2.PublicKey().IsEqual(esec.PublicKey()) {
+		t.Error("secret key does not round trip")
+	}
+
+	r, _ := new(big.Int).SetString(bls.GetCurveOrder(), 10)
+	for _, bad := range [][]byte{nil, make([]byte, 32), r.FillBytes(make([]byte, 32)), make([]byte, 33)} {
+		if esec2.Deserialize(bad) == nil {
+			t.Errorf("secret key %x deserializes", bad)
+		}
+	}
+}
+
+// TestETHSerializationMode checks that the eth encodings do not depend on SetETHserialization
+func TestETHSerializationMode(t *testing.T) {
+	var sec eth.SecretKey
+	sec.SetByCSPRNG()
+	encode := func() []byte {
+		var pub eth.PublicKey
+		var sig eth.Signature
+		if pub.Deserialize(sec.PublicKey().Serialize()) != nil || sig.Deserialize(sec.Sign([]byte("abc")).Serialize()) != nil {
+			t.Fatal("encoding does not round trip")
+		}
+		return append(pub.Serialize(), sig.Serialize()...)
+	}
+	buf := encode()
+	bls.SetETHserialization(true)
+	defer bls.SetETHserialization(false)
+	if !bytes.Equal(encode(), buf) {
+		t.Error("encodings depend on the serialization mode")
+	}
+}
+
+func TestETHInfinity(t *testing.T) {
+	var pub eth.PublicKey
+	var sig eth.Signature
+	if err := pub.Deserialize(append([]byte{0xc0}, make([]byte, eth.PublicKeySize-1)...)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sig.Deserialize(append([]byte{0xc0}, make([]byte, eth.SignatureSize-1)...)); err != nil {
+		t.Fatal(err)
+	}
+	if pub.IsValid() || eth.Verify(&pub, []byte("abc"), &sig) {
+		t.Error("public key at infinity accepted")
+	}
+	if _, err := eth.AggregatePublicKeys([]eth.PublicKey{pub}); err == nil {
+		t.Error("aggregated a public key at infinity")
+	}
+	if eth.FastAggregateVerify(nil, []byte("abc"), &sig) || !eth.EthFastAggregateVerify(nil, []byte("abc"), &sig) {
+		t.Error("bad verification of no public keys with the signature at infinity")
+	}
+}
@@ -0,0 +1,225 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+// Fuzz targets for the functions passing untrusted data to mcl
+// The seed corpus is in testdata/fuzz, run a target with e.g.
+//
+//	go test ./tests -run '^$' -fuzz FuzzPublicKeyDeserialize
+
+func FuzzFrDeserialize(f *testing.F) {
+	var x bls.Fr
+	x.SetByCSPRNG()
+	f.Add(x.Serialize())
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		var x, y bls.Fr
+		if x.Deserialize(buf) == nil {
+			if !bytes.Equal(x.Serialize(), buf) {
+				t.Fatalf("non canonical encoding accepted %x", buf)
+			}
+		}
+		if x.SetLittleEndian(buf) == nil {
+			if err := y.Deserialize(x.Serialize()); err != nil || !x.IsEqual(&y) {
+				t.Fatalf("round trip of SetLittleEndian(%x): %v", buf, err)
+			}
+		}
+		if len(buf) <= 64 && x.SetLittleEndianMod(buf) == nil {
+			if err := y.Deserialize(x.Serialize()); err != nil || !x.IsEqual(&y) {
+				t.Fatalf("round trip of SetLittleEndianMod(%x): %v", buf, err)
+			}
+		}
+	})
+}
+
+func FuzzPublicKeyDeserialize(f *testing.F) {
+	var sec bls.SecretKey
+	sec.SetByCSPRNG()
+	f.Add(sec.GetPublicKey().Serialize())
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		var pub bls.PublicKey
+		if pub.Deserialize(buf) != nil {
+			return
+		}
+		p := bls.CastFromPublicKey(&pub)
+		if !p.IsValid() || !p.IsValidOrder() {
+			t.Fatalf("invalid point accepted %x", buf)
+		}
+		if !bytes.Equal(pub.Serialize(), buf) {
+			t.Fatalf("non canonical encoding accepted %x", buf)
+		}
+		var pub2 bls.PublicKey
+		if err := pub2.DeserializeHexStr(pub.SerializeToHexStr()); err != nil || !pub.IsEqual(&pub2) {
+			t.Fatalf("round trip of %x: %v", buf, err)
+		}
+	})
+}
+
+func FuzzSignDeserialize(f *testing.F) {
+	var sec bls.SecretKey
+	sec.SetByCSPRNG()
+	f.Add(sec.Sign([]byte("fuzz")).Serialize())
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		var sig bls.Sign
+		if sig.Deserialize(buf) != nil {
+			return
+		}
+		p := bls.CastFromSign(&sig)
+		if !p.IsValid() || !p.IsValidOrder() {
+			t.Fatalf("invalid point accepted %x", buf)
+		}
+		if !bytes.Equal(sig.Serialize(), buf) {
+			t.Fatalf("non canonical encoding accepted %x", buf)
+		}
+		var sig2 bls.Sign
+		if err := sig2.DeserializeHexStr(sig.SerializeToHexStr()); err != nil || !sig.IsEqual(&sig2) {
+			t.Fatalf("round trip of %x: %v", buf, err)
+		}
+	})
+}
+
+func FuzzSecretKeyString(f *testing.F) {
+	sec := bls.NewSecretKey()
+	f.Add(sec.GetHexString())
+	f.Add(sec.GetDecString())
+	f.Add(sec.SerializeToHexStr())
+	f.Fuzz(func(t *testing.T, s string) {
+		var sec, sec2 bls.SecretKey
+		if sec.SetHexString(s) == nil {
+			if err := sec2.SetHexString(sec.GetHexString()); err != nil || !sec.IsEqual(&sec2) {
+				t.Fatalf("round trip of SetHexString(%q): %v", s, err)
+			}
+		}
+		if sec.SetDecString(s) == nil {
+			if err := sec2.SetDecString(sec.GetDecString()); err != nil || !sec.IsEqual(&sec2) {
+				t.Fatalf("round trip of SetDecString(%q): %v", s, err)
+			}
+		}
+		if sec.DeserializeHexStr(s) == nil {
+			if err := sec2.DeserializeHexStr(sec.SerializeToHexStr()); err != nil || !sec.IsEqual(&sec2) {
+				t.Fatalf("round trip of DeserializeHexStr(%q): %v", s, err)
+			}
+		}
+		var id, id2 bls.ID
+		if id.SetHexString(s) == nil {
+			if err := id2.SetHexString(id.GetHexString()); err != nil || !id.IsEqual(&id2) {
+				t.Fatalf("round trip of ID.SetHexString(%q): %v", s, err)
+			}
+		}
+		if id.SetDecString(s) == nil {
+			if err := id2.SetDecString(id.GetDecString()); err != nil || !id.IsEqual(&id2) {
+				t.Fatalf("round trip of ID.SetDecString(%q): %v", s, err)
+			}
+		}
+	})
+}
+
+func FuzzPointString(f *testing.F) {
+	sec := bls.NewSecretKey()
+	f.Add(sec.GetPublicKey().GetHexString())
+	f.Add(sec.GetPublicKey().SerializeToHexStr())
+	f.Add(sec.Sign([]byte("fuzz")).GetHexString())
+	f.Add(sec.Sign([]byte("fuzz")).SerializeToHexStr())
+	f.Fuzz(func(t *testing.T, s string) {
+		var pub, pub2 bls.PublicKey
+		if pub.SetHexString(s) == nil {
+			p := bls.CastFromPublicKey(&pub)
+			if !p.IsValid() || !p.IsValidOrder() {
+				t.Fatalf("invalid public key accepted %q", s)
+			}
+			if err := pub2.SetHexString(pub.GetHexString()); err != nil || !pub.IsEqual(&pub2) {
+				t.Fatalf("round trip of PublicKey.SetHexString(%q): %v", s, err)
+			}
+		}
+		if pub.DeserializeHexStr(s) == nil {
+			p := bls.CastFromPublicKey(&pub)
+			if !p.IsValid() || !p.IsValidOrder() {
+				t.Fatalf("invalid public key accepted %q", s)
+			}
+		}
+		var sig, sig2 bls.Sign
+		if sig.SetHexString(s) == nil {
+			p := bls.CastFromSign(&sig)
+			if !p.IsValid() || !p.IsValidOrder() {
+				t.Fatalf("invalid signature accepted %q", s)
+			}
+			if err := sig2.SetHexString(sig.GetHexString()); err != nil || !sig.IsEqual(&sig2) {
+				t.Fatalf("round trip of Sign.SetHexString(%q): %v", s, err)
+			}
+		}
+		if sig.DeserializeHexStr(s) == nil {
+			p := bls.CastFromSign(&sig)
+			if !p.IsValid() || !p.IsValidOrder() {
+				t.Fatalf("invalid signature accepted %q", s)
+			}
+		}
+	})
+}
+
+// FuzzLagrange interpolates shares of a random polynomial at ids taken from data, which may be zero or repeated
+func FuzzLagrange(f *testing.F) {
+	f.Add([]byte{1, 2, 3}, []byte("seed"))
+	f.Add([]byte{1, 1}, []byte("duplicate"))
+	f.Add([]byte{0, 5}, []byte("zero"))
+	f.Add([]byte{}, []byte("empty"))
+	f.Fuzz(func(t *testing.T, ids []byte, seed []byte) {
+		if len(ids) > 64 {
+			return
+		}
+		k := len(ids)
+		msk := make([]bls.SecretKey, k)
+		for i := range msk {
+			h := sha256.Sum256(append([]byte{byte(i)}, seed...))
+			if err := msk[i].SetHashOf(h[:]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		idVec := make([]bls.ID, k)
+		secVec := make([]bls.SecretKey, k)
+		pubVec := make([]bls.PublicKey, k)
+		signVec := make([]bls.Sign, k)
+		distinct := true
+		for i := range ids {
+			if err := idVec[i].SetLittleEndian([]byte{ids[i]}); err != nil {
+				t.Fatal(err)
+			}
+			distinct = distinct && ids[i] != 0 && bytes.IndexByte(ids[:i], ids[i]) < 0
+			if err := secVec[i].Set(msk, &idVec[i]); err != nil {
+				t.Fatal(err)
+			}
+			pubVec[i] = *secVec[i].GetPublicKey()
+			signVec[i] = *secVec[i].Sign([]byte("fuzz"))
+		}
+
+		var sec bls.SecretKey
+		var pub bls.PublicKey
+		var sig bls.Sign
+		errSec := sec.Recover(secVec, idVec)
+		errPub := pub.Recover(pubVec, idVec)
+		errSig := sig.Recover(signVec, idVec)
+		_, errBasis := bls.NewLagrangeBasis(idVec)
+		if !distinct || k == 0 {
+			if errSec == nil || errPub == nil || errSig == nil || errBasis == nil {
+				t.Fatalf("ids %v: interpolation accepted zero or duplicate ids", ids)
+			}
+			return
+		}
+		if errSec != nil || errPub != nil || errSig != nil || errBasis != nil {
+			t.Fatalf("ids %v: %v %v %v %v", ids, errSec, errPub, errSig, errBasis)
+		}
+		if !sec.IsEqual(&msk[0]) || !pub.IsEqual(msk[0].GetPublicKey()) || !sig.IsEqual(msk[0].Sign([]byte("fuzz"))) {
+			t.Fatalf("ids %v: bad interpolation", ids)
+		}
+		if k > 1 {
+			// mismatched sizes
+			if sec.Recover(secVec[1:], idVec) == nil || sig.Recover(signVec, idVec[1:]) == nil {
+				t.Fatalf("ids %v: mismatched sizes accepted", ids)
+			}
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/ibe"
+)
+
+func TestGeneratorOfG2(t *testing.T) {
+	var sec bls.SecretKey
+	bls.CastFromSecretKey(&sec).SetInt64(1)
+	q := bls.GetGeneratorOfG2()
+	if !sec.GetPublicKey().IsEqual(bls.CastToPublicKey(&q)) {
+		t.Error("public key of 1 is not the generator")
+	}
+	sign := sec.Sign([]byte("abc"))
+	var h bls.G1
+	if err := h.HashAndMapTo([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if !bls.CastFromSign(sign).IsEqual(&h) || !bls.CastToSign(&h).IsEqual(sign) {
+		t.Error("signature of 1 is not the message hash")
+	}
+}
+
+func TestIBE(t *testing.T) {
+	master := bls.NewSecretKey()
+	masterPub := master.GetPublicKey()
+	id := []byte("alice@spacemesh.io")
+	plaintext := []byte("attack at dawn")
+
+	key, err := ibe.Extract(&master, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ibe.VerifyKey(masterPub, id, key) {
+		t.Fatal("extracted key does not verify")
+	}
+	otherKey, err := ibe.Extract(&master, []byte("bob@spacemesh.io"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, encrypt := range []func(*bls.PublicKey, []byte, []byte) ([]byte, error){ibe.Encrypt, ibe.EncryptCCA} {
+		ciphertext, err := encrypt(masterPub, id, plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decrypted, err := ibe.Decrypt(key, ciphertext)
+		if err != nil {
+			t.Fatalf("version %d: %v", ciphertext[0], err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("version %d: bad plaintext %q", ciphertext[0], decrypted)
+		}
+		if _, err := ibe.Decrypt(otherKey, ciphertext); err != ibe.ErrDecrypt {
+			t.Errorf("version %d: decrypted with the key of another identity: %v", ciphertext[0], err)
+		}
+		// every modification is rejected
+		for _, i := range []int{1, 60, len(ciphertext) - 20, len(ciphertext) - 1} {
+			tampered := append([]byte{}, ciphertext...)
+			tampered[i] ^= 1
+			if _, err := ibe.Decrypt(key, tampered); err == nil {
+				t.Errorf("version %d: tampered byte %d decrypts", ciphertext[0], i)
+			}
+		}
+		if _, err := ibe.Decrypt(key, ciphertext[:40]); err == nil {
+			t.Errorf("version %d: truncated ciphertext decrypts", ciphertext[0])
+		}
+
+		// empty plaintexts are fine
+		ciphertext, err = encrypt(masterPub, id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decrypted, err := ibe.Decrypt(key, ciphertext); err != nil || len(decrypted) != 0 {
+			t.Errorf("empty plaintext: %q %v", decrypted, err)
+		}
+	}
+	if _, err := ibe.Encrypt(masterPub, nil, plaintext); err == nil {
+		t.Error("expected error for an empty identity")
+	}
+	if _, err := ibe.Extract(&master, nil); err == nil {
+		t.Error("expected error for extracting an empty identity")
+	}
+	if ibe.VerifyKey(masterPub, nil, key) {
+		t.Error("key verified for an empty identity")
+	}
+	var zeroPub bls.PublicKey
+	var zeroKey bls.Sign
+	if ibe.VerifyKey(&zeroPub, id, &zeroKey) {
+		t.Error("zero key verified under a zero master public key")
+	}
+}
+
+func TestIBEThresholdExtract(t *testing.T) {
+	// identity keys can be extracted by a threshold of key generators with Sign.Recover
+	master := bls.NewSecretKey()
+	msk := master.GetMasterSecretKey(2)
+	id := []byte("carol")
+	idVec := make([]bls.ID, 2)
+	signVec := make([]bls.Sign, 2)
+	for i := range idVec {
+		if err := idVec[i].SetLittleEndian([]byte{byte(i + 1)}); err != nil {
+			t.Fatal(err)
+		}
+		var share bls.SecretKey
+		if err := share.Set(msk, &idVec[i]); err != nil {
+			t.Fatal(err)
+		}
+		key, err := ibe.Extract(&share, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signVec[i] = *key
+	}
+	var key bls.Sign
+	if err := key.Recover(signVec, idVec); err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := ibe.EncryptCCA(master.GetPublicKey(), id, []byte("threshold"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted, err := ibe.Decrypt(&key, ciphertext); err != nil || string(decrypted) != "threshold" {
+		t.Errorf("bad decryption %q %v", decrypted, err)
+	}
+}
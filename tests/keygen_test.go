@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+func TestKeyGen(t *testing.T) {
+	ikm, err := hex.DecodeString("3141592653589793238462643383279502884197169399375105820974944592")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without key info KeyGen is the EIP-2333 master key derivation
+	sec, err := bls.KeyGen(ikm, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	master, err := bls.DeriveMasterSK(ikm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sec.IsEqual(&master) {
+		t.Error("KeyGen without key info does not match DeriveMasterSK")
+	}
+
+	sec, err = bls.KeyGen(ikm, []byte("spacemesh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "10983590328858002628532606711570634455858067819757357131333355031609789657575"
+	if sec.GetDecString() != expected {
+		t.Errorf("bad KeyGen with key info\n%s\n%s", sec.GetDecString(), expected)
+	}
+	sec2, err := bls.KeyGen(ikm, []byte("spacemesh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sec.IsEqual(&sec2) {
+		t.Error("KeyGen is not deterministic")
+	}
+
+	m := []byte("keygen")
+	if !sec.Sign(m).Verify(sec.GetPublicKey(), m) {
+		t.Error("Signature does not verify")
+	}
+}
+
+func TestKeyGenShortIKM(t *testing.T) {
+	if _, err := bls.KeyGen(make([]byte, bls.KeyGenMinIKMSize-1), nil); err == nil {
+		t.Error("expected error for short ikm")
+	}
+}
+
+func TestSecretKeySetHashOf(t *testing.T) {
+	var sec1, sec2 bls.SecretKey
+	if err := sec1.SetHashOf([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if err := sec2.SetHashOf([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if !sec1.IsEqual(&sec2) {
+		t.Error("SetHashOf is not deterministic")
+	}
+	var x bls.Fr
+	x.SetHashOf([]byte("abc"))
+	var sec3 bls.SecretKey
+	if err := sec3.SetLittleEndian(x.Serialize()); err != nil {
+		t.Fatal(err)
+	}
+	if !sec1.IsEqual(&sec3) {
+		t.Error("SetHashOf does not match Fr.SetHashOf")
+	}
+	if err := sec2.SetHashOf([]byte("abd")); err != nil {
+		t.Fatal(err)
+	}
+	if sec1.IsEqual(&sec2) {
+		t.Error("different inputs hash to the same key")
+	}
+	if err := sec2.SetHashOf(nil); err != nil {
+		t.Error(err)
+	}
+}
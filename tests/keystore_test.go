@@ -0,0 +1,222 @@
+package tests
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/keystore"
+)
+
+// test vectors from https://eips.ethereum.org/EIPS/eip-2335#test-cases
+const keystorePassword = "\U0001d531\U0001d522\U0001d530\U0001d531\U0001d52d\U0001d51e\U0001d530\U0001d530\U0001d534\U0001d52c\U0001d52f\U0001d521\U0001f511"
+const keystoreSecret = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+
+var keystoreVectors = []string{
+	`{
+    "crypto": {
+        "kdf": {
+            "function": "scrypt",
+            "params": {
+                "dklen": 32,
+                "n": 262144,
+                "p": 1,
+                "r": 8,
+                "salt": "d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3"
+            },
+            "message": ""
+        },
+        "checksum": {
+            "function": "sha256",
+            "params": {},
+            "message": "d2217fe5f3e9a1e34581ef8a78f7c9928e436d36dacc5e846690a5581e8ea484"
+        },
+        "cipher": {
+            "function": "aes-128-ctr",
+            "params": {
+                "iv": "264daa3f303d7259501c93d997d84fe6"
+            },
+            "message": "06ae90d55fe0a6e9c5c3bc5b170827b2e5cce3929ed3f116c2811e6366dfe20f"
+        }
+    },
+    "description": "This is a test keystore that uses scrypt to secure the secret.",
+    "pubkey": "9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07",
+    "path": "m/12381/60/3141592653/589793238",
+    "uuid": "1d85ae20-35c5-4611-98e8-aa14a633906f",
+    "version": 4
+}`,
+	`{
+    "crypto": {
+        "kdf": {
+            "function": "pbkdf2",
+            "params": {
+                "dklen": 32,
+                "c": 262144,
+                "prf": "hmac-sha256",
+                "salt": "d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3"
+            },
+            "message": ""
+        },
+        "checksum": {
+            "function": "sha256",
+            "params": {},
+            "message": "8a9f5d9912ed7e75ea794bc5a89bca5f193721d30868ade6f73043c6ea6febf1"
+        },
+        "cipher": {
+            "function": "aes-128-ctr",
+            "params": {
+                "iv": "264daa3f303d7259501c93d997d84fe6"
+            },
+            "message": "cee03fde2af33149775b7223e7845e4fb2c8ae1792e5f99fe9ecf474cc8c16ad"
+        }
+    },
+    "description": "This is a test keystore that uses PBKDF2 to secure the secret.",
+    "pubkey": "9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07",
+    "path": "m/12381/60/0/0",
+    "uuid": "64625def-3331-4eea-ab6f-782f3ed16a83",
+    "version": 4
+}`,
+}
+
+// secretKeyBigEndian returns the 32 byte big-endian hex encoding of sec
+func secretKeyBigEndian(sec *bls.SecretKey) string {
+	le := sec.GetLittleEndian()
+	be := make([]byte, 32)
+	for i := range le {
+		be[31-i] = le[i]
+	}
+	return hex.EncodeToString(be)
+}
+
+func TestKeystoreVectors(t *testing.T) {
+	for i, v := range keystoreVectors {
+		ks, err := keystore.Load([]byte(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sec, err := ks.Decrypt(keystorePassword)
+		if err != nil {
+			t.Fatalf("vector %d: %v", i, err)
+		}
+		if s := secretKeyBigEndian(&sec); s != keystoreSecret {
+			t.Errorf("vector %d: bad secret\n%s\n%s", i, s, keystoreSecret)
+		}
+		if _, err := ks.Decrypt("wrong password"); err != keystore.ErrInvalidPassword {
+			t.Errorf("vector %d: expected ErrInvalidPassword, got %v", i, err)
+		}
+	}
+}
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	sec := bls.NewSecretKey()
+	for _, kdf := range []string{keystore.KDFScrypt, keystore.KDFPBKDF2} {
+		ks, err := keystore.EncryptWithKDF(&sec, "pässword\x07", "m/12381/3600/0/0", kdf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ks.Pubkey != hex.EncodeToString(sec.GetPublicKey().Serialize()) {
+			t.Errorf("%s: bad pubkey", kdf)
+		}
+		data, err := ks.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ks2, err := keystore.Load(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// control code stripping makes these passwords equivalent
+		sec2, err := ks2.Decrypt("pässword")
+		if err != nil {
+			t.Fatalf("%s: %v", kdf, err)
+		}
+		if !sec.IsEqual(&sec2) {
+			t.Errorf("%s: secret key not same", kdf)
+		}
+	}
+	if _, err := keystore.Encrypt(&sec, "password", "m/44/60/0/0"); err == nil {
+		t.Error("expected error for a path without the EIP-2334 purpose")
+	}
+	if _, err := keystore.EncryptWithKDF(&sec, "password", "", "argon2"); err == nil {
+		t.Error("expected error for unsupported kdf")
+	}
+}
+
+func TestKeystoreNFKD(t *testing.T) {
+	sec := bls.NewSecretKey()
+	// composed and decomposed a with diaeresis have the same NFKD form
+	ks, err := keystore.EncryptWithKDF(&sec, "p\u00e4ssword", "", keystore.KDFPBKDF2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec2, err := ks.Decrypt("pa\u0308ssword")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sec.IsEqual(&sec2) {
+		t.Error("secret key not same")
+	}
+	if _, err := ks.Decrypt("password"); err != keystore.ErrInvalidPassword {
+		t.Errorf("expected ErrInvalidPassword without the diaeresis, got %v", err)
+	}
+}
+
+func TestKeystoreScryptBounds(t *testing.T) {
+	const ks = `{"crypto": {"kdf": {"function": "scrypt", "params": {"dklen": 32, "n": %d, "p": %d, "r": %d, "salt": "00"}, "message": ""},
+	"checksum": {"function": "sha256", "params": {}, "message": "00"},
+	"cipher": {"function": "aes-128-ctr", "params": {"iv": "00000000000000000000000000000000"}, "message": "%s"}},
+	"pubkey": "", "path": "", "uuid": "1d85ae20-35c5-4611-98e8-aa14a633906f", "version": 4}`
+	secret := strings.Repeat("00", 32)
+	for _, p := range [][3]int{{1 << 21, 1, 8}, {1 << 20, 1, 16}, {1 << 10, 1 << 20, 8}, {1 << 10, 1, 1 << 40}, {1, 1, 8}, {1 << 10, 0, 8}} {
+		k, err := keystore.Load([]byte(fmt.Sprintf(ks, p[0], p[1], p[2], secret)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := k.Decrypt("password"); err == nil || err == keystore.ErrInvalidPassword {
+			t.Errorf("n=%d p=%d r=%d: expected error for out of bounds params, got %v", p[0], p[1], p[2], err)
+		}
+	}
+	// small parameters are fine
+	k, err := keystore.Load([]byte(fmt.Sprintf(ks, 1<<10, 1, 8, secret)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.Decrypt("password"); err != keystore.ErrInvalidPassword {
+		t.Errorf("expected ErrInvalidPassword, got %v", err)
+	}
+}
+
+func TestKeystorePBKDF2Bounds(t *testing.T) {
+	const ks = `{"crypto": {"kdf": {"function": "pbkdf2", "params": {"dklen": 32, "c": %d, "prf": "hmac-sha256", "salt": "00"}, "message": ""},
+	"checksum": {"function": "sha256", "params": {}, "message": "00"},
+	"cipher": {"function": "aes-128-ctr", "params": {"iv": "00000000000000000000000000000000"}, "message": "%s"}},
+	"pubkey": "", "path": "", "uuid": "1d85ae20-35c5-4611-98e8-aa14a633906f", "version": 4}`
+	secret := strings.Repeat("00", 32)
+	for _, c := range []int{0, 1<<24 + 1, 1 << 31} {
+		k, err := keystore.Load([]byte(fmt.Sprintf(ks, c, secret)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := k.Decrypt("password"); err == nil || err == keystore.ErrInvalidPassword {
+			t.Errorf("c=%d: expected error for an out of bounds count, got %v", c, err)
+		}
+	}
+	k, err := keystore.Load([]byte(fmt.Sprintf(ks, 1<<10, secret)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.Decrypt("password"); err != keystore.ErrInvalidPassword {
+		t.Errorf("expected ErrInvalidPassword, got %v", err)
+	}
+}
+
+func TestKeystoreBadInput(t *testing.T) {
+	if _, err := keystore.Load([]byte(`{"version": 3}`)); err == nil {
+		t.Error("expected error for version 3")
+	}
+	if _, err := keystore.Load([]byte(`not json`)); err == nil {
+		t.Error("expected error for bad json")
+	}
+}
@@ -0,0 +1,368 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/kzg"
+)
+
+func newKZGSetup(t testing.TB, n int, nG2 int) (*kzg.Setup, *bls.Fr) {
+	var tau bls.Fr
+	tau.SetByCSPRNG()
+	s, err := kzg.NewInsecureSetup(&tau, n, nG2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, &tau
+}
+
+func randomPolynomial(n int) kzg.Polynomial {
+	p := make(kzg.Polynomial, n)
+	for i := range p {
+		p[i].SetByCSPRNG()
+	}
+	return p
+}
+
+// compressed encodings of points, checked once against SetETHserialization
+const (
+	kzgG1Generator = "97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb"
+	kzgG2Generator = "93e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e" +
+		"024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8"
+)
+
+func TestKZGEncoding(t *testing.T) {
+	var g1, h1, p1 bls.G1
+	if err := kzg.DecodeG1(mustDecodeHex(t, kzgG1Generator), &g1); err != nil {
+		t.Fatal(err)
+	}
+	if err := h1.HashAndMapTo([]byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	var g2, h2, p2 bls.G2
+	if err := kzg.DecodeG2(mustDecodeHex(t, kzgG2Generator), &g2); err != nil {
+		t.Fatal(err)
+	}
+	if err := h2.HashAndMapTo([]byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	var two bls.Fr
+	two.SetInt64(2)
+	g1Tests := []struct {
+		p   func(p *bls.G1)
+		enc string
+	}{
+		{func(p *bls.G1) { *p = g1 }, kzgG1Generator},
+		{func(p *bls.G1) { bls.G1Mul(p, &g1, &two) }, "a572cbea904d67468808c8eb50a9450c9721db309128012543902d0ac358a62ae28f75bb8f1c7c42c39a8c5529bf0f4e"},
+		{func(p *bls.G1) { *p = h1 }, "aebe9144bb2a1ea653f2084aa19ceb6f227c7f37d88201e906123563030d384742bb60acb5935bf21bd389ebf09b6a65"},
+		{func(p *bls.G1) { bls.G1Neg(p, &h1) }, "8ebe9144bb2a1ea653f2084aa19ceb6f227c7f37d88201e906123563030d384742bb60acb5935bf21bd389ebf09b6a65"},
+		{func(p *bls.G1) { p.Clear() }, "c0" + strings.Repeat("00", 47)},
+	}
+	for i, tt := range g1Tests {
+		var p bls.G1
+		tt.p(&p)
+		if enc := kzg.EncodeG1(&p); hex.EncodeToString(enc[:]) != tt.enc {
+			t.Errorf("G1 %d: bad encoding %x", i, enc)
+		}
+		if err := kzg.DecodeG1(mustDecodeHex(t, tt.enc), &p1); err != nil || !p1.IsEqual(&p) {
+			t.Errorf("G1 %d: bad decoding %v", i, err)
+		}
+	}
+	g2Tests := []struct {
+		p   func(p *bls.G2)
+		enc string
+	}{
+		{func(p *bls.G2) { *p = g2 }, kzgG2Generator},
+		{func(p *bls.G2) { *p = h2 }, "8e26dbb2a9d98207c7f21822dc04afe69133f1fa03736cb9a49f54d3caae2cdb6013f32ba84c37af8d23529a74820570" +
+			"0ce664c15268269b38769503b41766400d7870630aaefd89f9c129ac04dba312b314003ed86ffb584c1829dd43015980"},
+		{func(p *bls.G2) { bls.G2Neg(p, &h2) }, "ae26dbb2a9d98207c7f21822dc04afe69133f1fa03736cb9a49f54d3caae2cdb6013f32ba84c37af8d23529a74820570" +
+			"0ce664c15268269b38769503b41766400d7870630aaefd89f9c129ac04dba312b314003ed86ffb584c1829dd43015980"},
+		{func(p *bls.G2) { p.HashAndMapTo([]byte("2")) }, "92ed9ed0cbcc746ed1b5e7a2370dbf2b49298197e9c28d93992eb6e48252fa6eadda6f68be5aa39b2800bdbab7a229c6" +
+			"19e56022416b4d31458339595469039f64f57105231d12bbd1fa7bc4be73bffb191a7125572d2c47208ac24469a69cc9"},
+		{func(p *bls.G2) { p.Clear() }, "c0" + strings.Repeat("00", 95)},
+	}
+	for i, tt := range g2Tests {
+		var p bls.G2
+		tt.p(&p)
+		if enc := kzg.EncodeG2(&p); hex.EncodeToString(enc[:]) != tt.enc {
+			t.Errorf("G2 %d: bad encoding %x", i, enc)
+		}
+		if err := kzg.DecodeG2(mustDecodeHex(t, tt.enc), &p2); err != nil || !p2.IsEqual(&p) {
+			t.Errorf("G2 %d: bad decoding %v", i, err)
+		}
+	}
+
+	for _, s := range []string{
+		"00" + strings.Repeat("00", 47),        // uncompressed
+		"e0" + strings.Repeat("00", 47),        // infinity with sign
+		"c0" + strings.Repeat("00", 46) + "01", // infinity with x
+		"9a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab", // x = p
+		"97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6",   // short
+	} {
+		buf, _ := hex.DecodeString(s)
+		if kzg.DecodeG1(buf, &p1) == nil {
+			t.Errorf("decoded %s", s)
+		}
+	}
+	buf := make([]byte, kzg.G2Size)
+	buf[0] = 0xc0
+	buf[kzg.G2Size-1] = 1
+	if kzg.DecodeG2(buf, &p2) == nil {
+		t.Error("decoded infinity with x")
+	}
+
+	var x bls.Fr
+	x.SetInt64(-1)
+	enc := kzg.EncodeFr(&x)
+	if hex.EncodeToString(enc[:]) != "73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000000" {
+		t.Errorf("bad encoding of -1 %x", enc)
+	}
+	var y bls.Fr
+	if err := kzg.DecodeFr(enc[:], &y); err != nil || !y.IsEqual(&x) {
+		t.Errorf("bad decoding of -1 %v", err)
+	}
+	enc[31]++
+	if kzg.DecodeFr(enc[:], &y) == nil {
+		t.Error("decoded the order of the group")
+	}
+}
+
+func TestKZGOpen(t *testing.T) {
+	const n = 16
+	s, tau := newKZGSetup(t, n, 4)
+	p := randomPolynomial(n)
+	c, err := s.Commit(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// C = [p(tau)]G1 and G1 is the commitment to the constant 1
+	y, err := s.Evaluate(p, tau)
+	if err != nil {
+		t.Fatal(err)
+	}
+	one := make(kzg.Polynomial, n)
+	for i := range one {
+		one[i].SetInt64(1)
+	}
+	g1, _ := s.Commit(one)
+	var expected bls.G1
+	bls.G1Mul(&expected, g1, y)
+	if !c.IsEqual(&expected) {
+		t.Fatal("commitment is not [p(tau)]G1")
+	}
+
+	roots := s.Roots()
+	var z bls.Fr
+	z.SetByCSPRNG()
+	for _, point := range []*bls.Fr{&z, &roots[0], &roots[5]} {
+		proof, y, err := s.Open(p, point)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.Verify(c, point, y, proof) {
+			t.Error("proof does not verify")
+		}
+		var other bls.Fr
+		bls.FrAdd(&other, y, &roots[1])
+		if s.Verify(c, point, &other, proof) {
+			t.Error("proof verifies for another value")
+		}
+	}
+	if y, _ := s.Evaluate(p, &roots[5]); !y.IsEqual(&p[5]) {
+		t.Error("bad evaluation at a root")
+	}
+	if _, err := s.Commit(p[:n-1]); err == nil {
+		t.Error("expected error for a short polynomial")
+	}
+}
+
+func TestKZGOpenMulti(t *testing.T) {
+	const n = 32
+	s, _ := newKZGSetup(t, n, 6)
+	if s.MaxOpeningPoints() != 5 {
+		t.Fatalf("bad max opening points %d", s.MaxOpeningPoints())
+	}
+	p := randomPolynomial(n)
+	c, _ := s.Commit(p)
+	for k := 1; k <= s.MaxOpeningPoints(); k++ {
+		zs := randomPolynomial(k)
+		proof, ys, err := s.OpenMulti(p, zs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := range zs {
+			if y, _ := s.Evaluate(p, &zs[j]); !y.IsEqual(&ys[j]) {
+				t.Errorf("k=%d: bad evaluation %d", k, j)
+			}
+		}
+		if !s.VerifyMulti(c, zs, ys, proof) {
+			t.Errorf("k=%d: proof does not verify", k)
+		}
+		ys[k-1].SetByCSPRNG()
+		if s.VerifyMulti(c, zs, ys, proof) {
+			t.Errorf("k=%d: proof verifies for another value", k)
+		}
+		if s.VerifyMulti(c, zs[:k-1], ys[:k-1], proof) {
+			t.Errorf("k=%d: proof verifies for fewer points", k)
+		}
+	}
+	zs := randomPolynomial(2)
+	if _, _, err := s.OpenMulti(p, []bls.Fr{zs[0], zs[0]}); err == nil {
+		t.Error("expected error for duplicate points")
+	}
+	if _, _, err := s.OpenMulti(p, []bls.Fr{zs[0], s.Roots()[3]}); err == nil {
+		t.Error("expected error for a point of the domain")
+	}
+	if _, _, err := s.OpenMulti(p, randomPolynomial(s.MaxOpeningPoints()+1)); err == nil {
+		t.Error("expected error for too many points")
+	}
+}
+
+func TestKZGTrustedSetup(t *testing.T) {
+	const n = 8
+	s, tau := newKZGSetup(t, n, 3)
+	var buf bytes.Buffer
+	if _, err := s.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := kzg.LoadTrustedSetup(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := randomPolynomial(n)
+	c1, _ := s.Commit(p)
+	c2, _ := loaded.Commit(p)
+	if !c1.IsEqual(c2) {
+		t.Error("loaded setup gives another commitment")
+	}
+
+	// the format of c-kzg with the monomial G1 points after the G2 points
+	lines := strings.Fields(buf.String())
+	var g bls.G1
+	if err := kzg.DecodeG1(mustDecodeHex(t, kzgG1Generator), &g); err != nil {
+		t.Fatal(err)
+	}
+	monomial := make([]string, n)
+	for i := range monomial {
+		enc := kzg.EncodeG1(&g)
+		monomial[i] = hex.EncodeToString(enc[:])
+		bls.G1Mul(&g, &g, tau)
+	}
+	withMonomial := append(append([]string{}, lines...), monomial...)
+	loaded, err = kzg.LoadTrustedSetup(strings.NewReader(strings.Join(withMonomial, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := loaded.Commit(p); !c.IsEqual(c1) {
+		t.Error("setup with monomial points gives another commitment")
+	}
+	withMonomial[len(lines)], withMonomial[len(lines)+1] = withMonomial[len(lines)+1], withMonomial[len(lines)]
+	if _, err := kzg.LoadTrustedSetup(strings.NewReader(strings.Join(withMonomial, "\n"))); err == nil {
+		t.Error("expected error for swapped monomial points")
+	}
+	before := append(append(append([]string{}, lines[:2]...), monomial...), lines[2:]...)
+	if _, err := kzg.LoadTrustedSetup(strings.NewReader(strings.Join(before, "\n"))); err == nil {
+		t.Error("expected error for monomial points before the Lagrange points")
+	}
+
+	swapped := append([]string{}, lines...)
+	swapped[2], swapped[3] = swapped[3], swapped[2]
+	if _, err := kzg.LoadTrustedSetup(strings.NewReader(strings.Join(swapped, "\n"))); err == nil {
+		t.Error("expected error for swapped G1 points")
+	}
+	swapped = append([]string{}, lines...)
+	swapped[len(lines)-1], swapped[len(lines)-2] = swapped[len(lines)-2], swapped[len(lines)-1]
+	if _, err := kzg.LoadTrustedSetup(strings.NewReader(strings.Join(swapped, "\n"))); err == nil {
+		t.Error("expected error for swapped G2 points")
+	}
+	if _, err := kzg.LoadTrustedSetup(strings.NewReader(strings.Join(lines[:len(lines)-1], "\n"))); err == nil {
+		t.Error("expected error for a truncated setup")
+	}
+}
+
+func randomBlob(n int) []byte {
+	blob := make([]byte, 0, n*kzg.FrSize)
+	for _, x := range randomPolynomial(n) {
+		enc := kzg.EncodeFr(&x)
+		blob = append(blob, enc[:]...)
+	}
+	return blob
+}
+
+func TestKZGBlob(t *testing.T) {
+	s, _ := newKZGSetup(t, kzg.FieldElementsPerBlob, 65)
+	const nBlobs = 3
+	blobs := make([][]byte, nBlobs)
+	commitments := make([]kzg.Commitment, nBlobs)
+	proofs := make([]kzg.Proof, nBlobs)
+	for i := range blobs {
+		blobs[i] = randomBlob(kzg.FieldElementsPerBlob)
+		if len(blobs[i]) != kzg.BytesPerBlob {
+			t.Fatalf("bad blob size %d", len(blobs[i]))
+		}
+		var err error
+		if commitments[i], err = s.BlobToCommitment(blobs[i]); err != nil {
+			t.Fatal(err)
+		}
+		if proofs[i], err = s.ComputeBlobProof(blobs[i], commitments[i]); err != nil {
+			t.Fatal(err)
+		}
+		if !s.VerifyBlobProof(blobs[i], commitments[i], proofs[i]) {
+			t.Errorf("blob %d: proof does not verify", i)
+		}
+	}
+	if !s.VerifyBlobProofBatch(blobs, commitments, proofs) {
+		t.Error("batch does not verify")
+	}
+	if !s.VerifyBlobProofBatch(nil, nil, nil) {
+		t.Error("empty batch does not verify")
+	}
+	proofs[0], proofs[1] = proofs[1], proofs[0]
+	if s.VerifyBlobProofBatch(blobs, commitments, proofs) || s.VerifyBlobProof(blobs[0], commitments[0], proofs[0]) {
+		t.Error("swapped proofs verify")
+	}
+	proofs[0], proofs[1] = proofs[1], proofs[0]
+
+	var z bls.Fr
+	z.SetByCSPRNG()
+	proof, y, err := s.ComputeProof(blobs[0], kzg.EncodeFr(&z))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.VerifyProof(commitments[0], kzg.EncodeFr(&z), y, proof) {
+		t.Error("proof does not verify")
+	}
+	if s.VerifyProof(commitments[1], kzg.EncodeFr(&z), y, proof) {
+		t.Error("proof verifies for another commitment")
+	}
+
+	// the zero blob commits to infinity
+	zero := make([]byte, kzg.BytesPerBlob)
+	c, err := s.BlobToCommitment(zero)
+	if err != nil || c != (kzg.Commitment{0xc0}) {
+		t.Errorf("bad commitment to the zero blob %x %v", c, err)
+	}
+	bad := append([]byte{}, blobs[0]...)
+	copy(bad[kzg.FrSize:], bytes.Repeat([]byte{0xff}, kzg.FrSize))
+	if _, err := s.BlobToCommitment(bad); err == nil {
+		t.Error("expected error for a non canonical element")
+	}
+	if s.VerifyBlobProof(bad, commitments[0], proofs[0]) {
+		t.Error("proof verifies for a non canonical blob")
+	}
+	if _, err := s.BlobToCommitment(blobs[0][:kzg.BytesPerBlob-1]); err == nil {
+		t.Error("expected error for a short blob")
+	}
+}
+
+func mustDecodeHex(t testing.TB, s string) []byte {
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
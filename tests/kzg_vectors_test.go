@@ -0,0 +1,269 @@
+package tests
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/kzg"
+)
+
+// Known answers of the EIP-4844 functions in the format of the KZG vectors of the consensus-spec tests
+// (see testdata/vectors/README.md). A null output means that the inputs are invalid.
+//
+// The pinned vectors of testdata/vectors/kzg/pinned are checked in and run with the insecure setup of
+// kzgVectorsTau. The consensus-spec vectors are run with the trusted_setup.txt of c-kzg when both are
+// fetched into testdata/vectors/kzg.
+
+// kzgVectorsTau -- tau of the setup of the pinned vectors, SHA-256("go-bls insecure kzg setup") mod r
+const kzgVectorsTau = "16506939572250868448425370241196120258941073581762688716819243065841465428644"
+
+var kzgHandlers = map[string]func(t *testing.T, s *kzg.Setup, v *ethVector){
+	"blob_to_kzg_commitment":      testKZGBlobToCommitment,
+	"compute_kzg_proof":           testKZGComputeProof,
+	"verify_kzg_proof":            testKZGVerifyProof,
+	"compute_blob_kzg_proof":      testKZGComputeBlobProof,
+	"verify_blob_kzg_proof":       testKZGVerifyBlobProof,
+	"verify_blob_kzg_proof_batch": testKZGVerifyBlobProofBatch,
+}
+
+func TestKZGVectors(t *testing.T) {
+	var tau bls.Fr
+	if err := tau.SetString(kzgVectorsTau, 10); err != nil {
+		t.Fatal(err)
+	}
+	s, err := kzg.NewInsecureSetup(&tau, kzg.FieldElementsPerBlob, 65)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runKZGVectors(t, s, filepath.Join(vectorsDir, "kzg", "pinned"))
+}
+
+func TestKZGConsensusSpecVectors(t *testing.T) {
+	root := filepath.Join(vectorsDir, "kzg")
+	setupPath := filepath.Join(root, "trusted_setup.txt")
+	if _, err := os.Stat(setupPath); os.IsNotExist(err) {
+		t.Skipf("no %s, see %s/README.md", setupPath, vectorsDir)
+	}
+	s, err := kzg.LoadTrustedSetupFile(setupPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Size() != kzg.FieldElementsPerBlob {
+		t.Fatalf("setup of %d points", s.Size())
+	}
+	runKZGVectors(t, s, filepath.Join(root, "tests"))
+}
+
+// runKZGVectors runs the vectors under root, failing unless every handler has some
+func runKZGVectors(t *testing.T, s *kzg.Setup, root string) {
+	ran := map[string]int{}
+	for _, file := range vectorFiles(t, root) {
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handler := kzgHandler(rel)
+		run, ok := kzgHandlers[handler]
+		if !ok {
+			continue
+		}
+		ran[handler]++
+		t.Run(filepath.ToSlash(rel), func(t *testing.T) {
+			buf, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var v ethVector
+			if err := json.Unmarshal(buf, &v); err != nil {
+				t.Fatal(err)
+			}
+			run(t, s, &v)
+		})
+	}
+	for handler := range kzgHandlers {
+		if ran[handler] == 0 {
+			t.Errorf("no %s vectors in %s", handler, root)
+		}
+	}
+}
+
+// kzgHandler returns the handler of .../kzg/<handler>/kzg-mainnet/<case>/data.json
+func kzgHandler(rel string) string {
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		if _, ok := kzgHandlers[part]; ok {
+			return part
+		}
+	}
+	return ""
+}
+
+func testKZGBlobToCommitment(t *testing.T, s *kzg.Setup, v *ethVector) {
+	var in struct {
+		Blob string `json:"blob"`
+	}
+	var out *string
+	decodeVector(t, v, &in, &out)
+	blob, ok := vectorHex(in.Blob, kzg.BytesPerBlob)
+	var c kzg.Commitment
+	if ok {
+		var err error
+		c, err = s.BlobToCommitment(blob)
+		ok = err == nil
+	}
+	checkKZGOutput(t, ok, c[:], out)
+}
+
+func testKZGComputeProof(t *testing.T, s *kzg.Setup, v *ethVector) {
+	var in struct {
+		Blob string `json:"blob"`
+		Z    string `json:"z"`
+	}
+	var out *[2]string
+	decodeVector(t, v, &in, &out)
+	blob, ok := vectorHex(in.Blob, kzg.BytesPerBlob)
+	z, okZ := vectorHex(in.Z, kzg.FrSize)
+	var proof kzg.Proof
+	var y [kzg.FrSize]byte
+	if ok = ok && okZ; ok {
+		var err error
+		proof, y, err = s.ComputeProof(blob, [kzg.FrSize]byte(z))
+		ok = err == nil
+	}
+	if out == nil {
+		checkKZGOutput(t, ok, nil, nil)
+		return
+	}
+	checkKZGOutput(t, ok, proof[:], &out[0])
+	checkKZGOutput(t, ok, y[:], &out[1])
+}
+
+func testKZGVerifyProof(t *testing.T, s *kzg.Setup, v *ethVector) {
+	var in struct {
+		Commitment string `json:"commitment"`
+		Z          string `json:"z"`
+		Y          string `json:"y"`
+		Proof      string `json:"proof"`
+	}
+	var out *bool
+	decodeVector(t, v, &in, &out)
+	c, okC := vectorHex(in.Commitment, kzg.G1Size)
+	z, okZ := vectorHex(in.Z, kzg.FrSize)
+	y, okY := vectorHex(in.Y, kzg.FrSize)
+	proof, okP := vectorHex(in.Proof, kzg.G1Size)
+	if !(okC && okZ && okY && okP) {
+		checkKZGVerify(t, false, false, out)
+		return
+	}
+	ok := s.VerifyProof(kzg.Commitment(c), [kzg.FrSize]byte(z), [kzg.FrSize]byte(y), kzg.Proof(proof))
+	checkKZGVerify(t, true, ok, out)
+}
+
+func testKZGComputeBlobProof(t *testing.T, s *kzg.Setup, v *ethVector) {
+	var in struct {
+		Blob       string `json:"blob"`
+		Commitment string `json:"commitment"`
+	}
+	var out *string
+	decodeVector(t, v, &in, &out)
+	blob, ok := vectorHex(in.Blob, kzg.BytesPerBlob)
+	c, okC := vectorHex(in.Commitment, kzg.G1Size)
+	var proof kzg.Proof
+	if ok = ok && okC; ok {
+		var err error
+		proof, err = s.ComputeBlobProof(blob, kzg.Commitment(c))
+		ok = err == nil
+	}
+	checkKZGOutput(t, ok, proof[:], out)
+}
+
+func testKZGVerifyBlobProof(t *testing.T, s *kzg.Setup, v *ethVector) {
+	var in struct {
+		Blob       string `json:"blob"`
+		Commitment string `json:"commitment"`
+		Proof      string `json:"proof"`
+	}
+	var out *bool
+	decodeVector(t, v, &in, &out)
+	blob, okB := vectorHex(in.Blob, kzg.BytesPerBlob)
+	c, okC := vectorHex(in.Commitment, kzg.G1Size)
+	proof, okP := vectorHex(in.Proof, kzg.G1Size)
+	if !(okB && okC && okP) {
+		checkKZGVerify(t, false, false, out)
+		return
+	}
+	ok := s.VerifyBlobProof(blob, kzg.Commitment(c), kzg.Proof(proof))
+	checkKZGVerify(t, true, ok, out)
+}
+
+func testKZGVerifyBlobProofBatch(t *testing.T, s *kzg.Setup, v *ethVector) {
+	var in struct {
+		Blobs       []string `json:"blobs"`
+		Commitments []string `json:"commitments"`
+		Proofs      []string `json:"proofs"`
+	}
+	var out *bool
+	decodeVector(t, v, &in, &out)
+	valid := true
+	blobs := make([][]byte, len(in.Blobs))
+	for i := range in.Blobs {
+		var ok bool
+		blobs[i], ok = vectorHex(in.Blobs[i], kzg.BytesPerBlob)
+		valid = valid && ok
+	}
+	commitments := make([]kzg.Commitment, len(in.Commitments))
+	for i := range in.Commitments {
+		c, ok := vectorHex(in.Commitments[i], kzg.G1Size)
+		if valid = valid && ok; valid {
+			commitments[i] = kzg.Commitment(c)
+		}
+	}
+	proofs := make([]kzg.Proof, len(in.Proofs))
+	for i := range in.Proofs {
+		p, ok := vectorHex(in.Proofs[i], kzg.G1Size)
+		if valid = valid && ok; valid {
+			proofs[i] = kzg.Proof(p)
+		}
+	}
+	if !valid {
+		checkKZGVerify(t, false, false, out)
+		return
+	}
+	checkKZGVerify(t, true, s.VerifyBlobProofBatch(blobs, commitments, proofs), out)
+}
+
+// checkKZGOutput -- the result of a compute handler, ok is false if it rejected its inputs
+func checkKZGOutput(t *testing.T, ok bool, result []byte, out *string) {
+	switch {
+	case out == nil && ok:
+		t.Error("expected invalid inputs")
+	case out != nil && !ok:
+		t.Error("valid inputs rejected")
+	case out != nil && hex.EncodeToString(result) != strings.TrimPrefix(*out, "0x"):
+		t.Errorf("bad output\n%x\n%s", result, *out)
+	}
+}
+
+// checkKZGVerify -- the result of a verify handler, valid is false for malformed inputs
+// The verify functions return false rather than an error for non-canonical points and field
+// elements, which the vectors expect to be errors, so only a true result is compared with them.
+func checkKZGVerify(t *testing.T, valid bool, ok bool, out *bool) {
+	switch {
+	case out == nil && ok:
+		t.Error("expected invalid inputs")
+	case out != nil && !valid:
+		t.Error("valid inputs rejected")
+	case out != nil && ok != *out:
+		t.Errorf("verified %v, expected %v", ok, *out)
+	}
+}
+
+// vectorHex decodes the 0x-prefixed hex s, reporting whether it has size bytes
+func vectorHex(s string, size int) ([]byte, bool) {
+	buf, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	return buf, err == nil && len(buf) == size
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+func makeShares(t testing.TB, k int, n int, m []byte) (*bls.SecretKey, []bls.ID, []bls.SecretKey, []bls.PublicKey, []bls.Sign) {
+	var sec bls.SecretKey
+	sec.SetByCSPRNG()
+	msk := sec.GetMasterSecretKey(k)
+	idVec := make([]bls.ID, n)
+	secVec := make([]bls.SecretKey, n)
+	pubVec := make([]bls.PublicKey, n)
+	signVec := make([]bls.Sign, n)
+	for i := 0; i < n; i++ {
+		err := idVec[i].SetLittleEndian([]byte{1, 2, 3, 4, 5, byte(i), byte(i >> 8)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = secVec[i].Set(msk, &idVec[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubVec[i] = *secVec[i].GetPublicKey()
+		signVec[i] = *secVec[i].Sign(m)
+	}
+	return &sec, idVec, secVec, pubVec, signVec
+}
+
+func TestLagrangeBasisRecover(t *testing.T) {
+	m := []byte("testLagrangeBasis")
+	for _, k := range []int{1, 3, 7, 40} {
+		sec, idVec, secVec, pubVec, signVec := makeShares(t, k, k, m)
+		basis, err := bls.NewLagrangeBasis(idVec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var sec2 bls.SecretKey
+		if err := sec2.RecoverWithBasis(secVec, basis); err != nil {
+			t.Fatal(err)
+		}
+		if !sec.IsEqual(&sec2) {
+			t.Errorf("k=%d: mismatch in recovered secret key", k)
+		}
+
+		var pub2 bls.PublicKey
+		if err := pub2.RecoverWithBasis(pubVec, basis); err != nil {
+			t.Fatal(err)
+		}
+		if !sec.GetPublicKey().IsEqual(&pub2) {
+			t.Errorf("k=%d: mismatch in recovered public key", k)
+		}
+
+		var sign1, sign2 bls.Sign
+		if err := sign1.Recover(signVec, idVec); err != nil {
+			t.Fatal(err)
+		}
+		if err := sign2.RecoverWithBasis(signVec, basis); err != nil {
+			t.Fatal(err)
+		}
+		if !sign1.IsEqual(&sign2) {
+			t.Errorf("k=%d: mismatch in recovered signature", k)
+		}
+		if !sign2.Verify(&pub2, m) {
+			t.Errorf("k=%d: recovered signature does not verify", k)
+		}
+	}
+}
+
+func TestLagrangeBasisBadIDs(t *testing.T) {
+	if _, err := bls.NewLagrangeBasis(nil); err == nil {
+		t.Error("expected error for empty ids")
+	}
+	idVec := make([]bls.ID, 3)
+	for i := range idVec {
+		if err := idVec[i].SetDecString("5"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := bls.NewLagrangeBasis(idVec); err == nil {
+		t.Error("expected error for duplicate ids")
+	}
+	if err := idVec[0].SetDecString("0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := idVec[1].SetDecString("1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bls.NewLagrangeBasis(idVec); err == nil {
+		t.Error("expected error for zero id")
+	}
+}
+
+func TestLagrangeBasisBadSize(t *testing.T) {
+	_, idVec, secVec, _, signVec := makeShares(t, 3, 3, []byte("m"))
+	basis, err := bls.NewLagrangeBasis(idVec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sec bls.SecretKey
+	if err := sec.RecoverWithBasis(secVec[:2], basis); err == nil {
+		t.Error("expected error for short secret key vector")
+	}
+	var sign bls.Sign
+	if err := sign.RecoverWithBasis(signVec[:2], basis); err == nil {
+		t.Error("expected error for short signature vector")
+	}
+}
+
+func benchmarkRecoverSignatureWithBasis(k int, b *testing.B) {
+	b.StopTimer()
+	_, idVec, _, _, signVec := makeShares(b, k, k, []byte("test message"))
+	basis, err := bls.NewLagrangeBasis(idVec)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var sig bls.Sign
+	b.StartTimer()
+	for n := 0; n < b.N; n++ {
+		err := sig.RecoverWithBasis(signVec, basis)
+		if err != nil {
+			b.Error(err)
+		}
+	}
+}
+
+func BenchmarkRecoverSignatureBasis100(b *testing.B)  { benchmarkRecoverSignatureWithBasis(100, b) }
+func BenchmarkRecoverSignatureBasis1000(b *testing.B) { benchmarkRecoverSignatureWithBasis(1000, b) }
+
+func BenchmarkNewLagrangeBasis1000(b *testing.B) {
+	b.StopTimer()
+	_, idVec, _, _, _ := makeShares(b, 1, 1000, []byte("test message"))
+	b.StartTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := bls.NewLagrangeBasis(idVec); err != nil {
+			b.Error(err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+func TestMultiSignature(t *testing.T) {
+	const n = 10
+	secVec, pubVec := makeCommittee(n)
+	msg := []byte("multisignature message")
+	sigVec := make([]bls.Sign, n)
+	for i := range secVec {
+		sigVec[i] = *secVec[i].Sign(msg)
+	}
+	sig, err := bls.AggregateMulti(pubVec, sigVec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sig.VerifyMulti(pubVec, msg) {
+		t.Fatal("multisignature does not verify")
+	}
+	pub, err := bls.AggregatePublicKeysMulti(pubVec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sig.Verify(pub, msg) {
+		t.Error("multisignature does not verify under the aggregate key")
+	}
+	if sig.VerifyMulti(pubVec, []byte("other message")) {
+		t.Error("multisignature verifies for another message")
+	}
+	if sig.VerifyMulti(pubVec[:n-1], msg) {
+		t.Error("multisignature verifies without a signer")
+	}
+	pubVec[0], pubVec[1] = pubVec[1], pubVec[0]
+	if sig.VerifyMulti(pubVec, msg) {
+		t.Error("multisignature verifies with reordered keys")
+	}
+	pubVec[0], pubVec[1] = pubVec[1], pubVec[0]
+
+	coefVec, err := bls.MultiCoefficients(pubVec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range coefVec {
+		if coefVec[i].IsZero() || len(coefVec[i].Serialize()) != 32 {
+			t.Errorf("bad coefficient %d", i)
+		}
+	}
+	if _, err := bls.AggregateMulti(pubVec, sigVec[:n-1]); err == nil {
+		t.Error("expected error for mismatched lengths")
+	}
+	if _, err := bls.AggregateMulti(nil, nil); err == nil {
+		t.Error("expected error for no signers")
+	}
+	if sig.VerifyMulti(nil, msg) {
+		t.Error("multisignature verifies without keys")
+	}
+}
+
+func TestMultiSignatureRogueKey(t *testing.T) {
+	var honest, attacker bls.SecretKey
+	honest.SetByCSPRNG()
+	attacker.SetByCSPRNG()
+	// rogue = attacker * Q - honest * Q, so that honest + rogue = attacker * Q
+	var rogue bls.G2
+	bls.G2Neg(&rogue, bls.CastFromPublicKey(honest.GetPublicKey()))
+	bls.G2Add(&rogue, &rogue, bls.CastFromPublicKey(attacker.GetPublicKey()))
+	pubVec := []bls.PublicKey{*honest.GetPublicKey(), *bls.CastToPublicKey(&rogue)}
+	msg := []byte("forged message")
+	forged := attacker.Sign(msg)
+
+	naive := pubVec[0]
+	naive.Add(&pubVec[1])
+	if !forged.Verify(&naive, msg) {
+		t.Fatal("rogue key attack does not work against plain aggregation")
+	}
+	if forged.VerifyMulti(pubVec, msg) {
+		t.Error("rogue key attack works against multisignatures")
+	}
+	var zero bls.PublicKey
+	if forged.VerifyMulti([]bls.PublicKey{pubVec[0], zero}, msg) {
+		t.Error("multisignature verifies with the zero key")
+	}
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("no entropy")
+}
+
+func TestSetRandReaderDeterministic(t *testing.T) {
+	prev := bls.SetRandReader(rand.New(rand.NewSource(1)))
+	sec1 := bls.NewSecretKey()
+	msk1 := sec1.GetMasterSecretKey(3)
+	bls.SetRandReader(rand.New(rand.NewSource(1)))
+	sec2 := bls.NewSecretKey()
+	msk2 := sec2.GetMasterSecretKey(3)
+	bls.SetRandReader(prev)
+
+	if !sec1.IsEqual(&sec2) {
+		t.Error("same stream gave different secret keys")
+	}
+	for i := range msk1 {
+		if !msk1[i].IsEqual(&msk2[i]) {
+			t.Errorf("same stream gave different master secret key %d", i)
+		}
+	}
+
+	// the default CSPRNG is restored
+	sec3 := bls.NewSecretKey()
+	if sec1.IsEqual(&sec3) {
+		t.Error("default CSPRNG was not restored")
+	}
+}
+
+func TestSetRandReaderRestore(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	prev := bls.SetRandReader(r)
+	if prev != nil {
+		t.Errorf("expected default reader, got %v", prev)
+	}
+	func() {
+		defer bls.SetRandReader(bls.SetRandReader(failingReader{}))
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic from a failing reader")
+			}
+		}()
+		bls.NewSecretKey()
+	}()
+	if cur := bls.SetRandReader(nil); cur != r {
+		t.Error("previous reader was not restored")
+	}
+	bls.NewSecretKey()
+}
+
+func TestSetRandReaderConcurrent(t *testing.T) {
+	defer bls.SetRandReader(nil)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				sec := bls.NewSecretKey()
+				if sec.GetPublicKey() == nil {
+					t.Error("no public key")
+				}
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		if i%2 == 0 {
+			bls.SetRandReader(rand.New(rand.NewSource(int64(i))))
+		} else {
+			bls.SetRandReader(nil)
+		}
+	}
+	wg.Wait()
+}
+
+func TestReadRand(t *testing.T) {
+	buf1 := make([]byte, 40)
+	buf2 := make([]byte, 40)
+	func() {
+		defer bls.SetRandReader(bls.SetRandReader(rand.New(rand.NewSource(3))))
+		if err := bls.ReadRand(buf1); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	func() {
+		defer bls.SetRandReader(bls.SetRandReader(rand.New(rand.NewSource(3))))
+		if err := bls.ReadRand(buf2); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	if string(buf1) != string(buf2) {
+		t.Error("same stream gave different bytes")
+	}
+	func() {
+		defer bls.SetRandReader(bls.SetRandReader(failingReader{}))
+		if err := bls.ReadRand(buf1); err == nil {
+			t.Error("expected error from a failing reader")
+		}
+	}()
+	if err := bls.ReadRand(buf2); err != nil || string(buf1) == string(buf2) {
+		t.Errorf("default reader: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/registry"
+)
+
+func TestRegistry(t *testing.T) {
+	const n = 6
+	r := registry.New()
+	secVec := make([]bls.SecretKey, n)
+	for i := range secVec {
+		secVec[i].SetByCSPRNG()
+		index, err := r.Register(secVec[i].GetPublicKey(), secVec[i].GetPop())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if index != uint32(i) {
+			t.Errorf("bad index %d for key %d", index, i)
+		}
+	}
+	// registering again keeps the index
+	if index, err := r.RegisterSerialized(secVec[2].GetPublicKey().Serialize(), secVec[2].GetPop().Serialize()); err != nil || index != 2 {
+		t.Errorf("re-registration: %d %v", index, err)
+	}
+	if r.Len() != n {
+		t.Errorf("bad length %d", r.Len())
+	}
+	if index, ok := r.Index(secVec[4].GetPublicKey()); !ok || index != 4 {
+		t.Errorf("bad index lookup %d %v", index, ok)
+	}
+	if pub, err := r.PublicKey(3); err != nil || !pub.IsEqual(secVec[3].GetPublicKey()) {
+		t.Errorf("bad key at index 3: %v", err)
+	}
+	if _, err := r.PublicKey(n); err == nil {
+		t.Error("expected error for unknown index")
+	}
+
+	msg := []byte("committee message")
+	indices := []uint32{5, 0, 3}
+	var sig bls.Sign
+	for k, i := range indices {
+		if k == 0 {
+			sig = *secVec[i].Sign(msg)
+		} else {
+			sig.Add(secVec[i].Sign(msg))
+		}
+	}
+	agg, err := r.AggregateByIndices(indices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sig.Verify(&agg, msg) {
+		t.Error("signature does not verify under the aggregated key")
+	}
+	if !r.FastAggregateVerify(indices, msg, &sig) {
+		t.Error("FastAggregateVerify failed")
+	}
+	for _, bad := range [][]uint32{{5, 0}, {5, 0, 3, 1}, {5, 0, 3, 3}, {5, 0, n}, nil} {
+		if r.FastAggregateVerify(bad, msg, &sig) {
+			t.Errorf("FastAggregateVerify accepted indices %v", bad)
+		}
+	}
+	if r.FastAggregateVerify(indices, []byte("other"), &sig) {
+		t.Error("FastAggregateVerify accepted another message")
+	}
+}
+
+func TestRegistryRejects(t *testing.T) {
+	r := registry.New()
+	var sec, other bls.SecretKey
+	sec.SetByCSPRNG()
+	other.SetByCSPRNG()
+	if _, err := r.Register(sec.GetPublicKey(), other.GetPop()); err != registry.ErrInvalidPop {
+		t.Errorf("expected ErrInvalidPop, got %v", err)
+	}
+	var zero bls.SecretKey
+	if _, err := r.Register(zero.GetPublicKey(), zero.GetPop()); err != registry.ErrInvalidKey {
+		t.Errorf("expected ErrInvalidKey for the zero key, got %v", err)
+	}
+	if _, err := r.RegisterSerialized([]byte{1, 2, 3}, sec.GetPop().Serialize()); err != registry.ErrInvalidKey {
+		t.Errorf("expected ErrInvalidKey for a bad encoding, got %v", err)
+	}
+	if r.Len() != 0 {
+		t.Errorf("rejected keys registered: %d", r.Len())
+	}
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	r := registry.New()
+	const n = 16
+	secVec := make([]bls.SecretKey, n)
+	for i := range secVec {
+		secVec[i].SetByCSPRNG()
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range secVec {
+				if _, err := r.Register(secVec[i].GetPublicKey(), secVec[i].GetPop()); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if r.Len() != n {
+		t.Errorf("bad length %d", r.Len())
+	}
+	for i := range secVec {
+		index, ok := r.Index(secVec[i].GetPublicKey())
+		if !ok {
+			t.Fatalf("key %d missing", i)
+		}
+		if pub, err := r.PublicKey(index); err != nil || !pub.IsEqual(secVec[i].GetPublicKey()) {
+			t.Errorf("key %d: bad index %d", i, index)
+		}
+	}
+}
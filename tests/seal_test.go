@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+func TestSealOpen(t *testing.T) {
+	sec := bls.NewSecretKey()
+	pub := sec.GetPublicKey()
+	plaintext := []byte("hello spacemesh")
+	aad := []byte("context")
+
+	ciphertext, err := bls.Seal(pub, plaintext, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ciphertext[0] != bls.SealVersion {
+		t.Errorf("bad version %d", ciphertext[0])
+	}
+	decrypted, err := bls.Open(&sec, ciphertext, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("bad plaintext %q", decrypted)
+	}
+
+	// ephemeral keys make every ciphertext different
+	ciphertext2, err := bls.Seal(pub, plaintext, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(ciphertext, ciphertext2) {
+		t.Error("same ciphertext twice")
+	}
+
+	if _, err := bls.Open(&sec, ciphertext, []byte("other context")); err == nil {
+		t.Error("opened with a different aad")
+	}
+	other := bls.NewSecretKey()
+	if _, err := bls.Open(&other, ciphertext, aad); err == nil {
+		t.Error("opened with another key")
+	}
+	for _, i := range []int{0, 1, 50, len(ciphertext) - 1} {
+		tampered := append([]byte{}, ciphertext...)
+		tampered[i] ^= 1
+		if _, err := bls.Open(&sec, tampered, aad); err == nil {
+			t.Errorf("tampered byte %d opens", i)
+		}
+	}
+	if _, err := bls.Open(&sec, ciphertext[:10], aad); err == nil {
+		t.Error("truncated ciphertext opens")
+	}
+	if _, err := bls.Open(&sec, nil, aad); err == nil {
+		t.Error("empty ciphertext opens")
+	}
+
+	ciphertext, err = bls.Seal(pub, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted, err := bls.Open(&sec, ciphertext, nil); err != nil || len(decrypted) != 0 {
+		t.Errorf("empty plaintext: %q %v", decrypted, err)
+	}
+
+	var zero bls.PublicKey
+	if _, err := bls.Seal(&zero, plaintext, nil); err == nil {
+		t.Error("sealed to the zero public key")
+	}
+}
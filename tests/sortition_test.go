@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/sortition"
+)
+
+func TestSortitionSelectVerify(t *testing.T) {
+	params := sortition.Params{CommitteeSize: 100, TotalWeight: 10000}
+	alpha := []byte("seed|round 7|proposer")
+	const n = 500
+	const weight = 20
+
+	total := uint64(0)
+	for i := 0; i < n; i++ {
+		sec := bls.NewSecretKey()
+		proof, seats, err := sortition.Select(&sec, alpha, weight, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		verified, err := sortition.Verify(sec.GetPublicKey(), alpha, &proof, weight, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if verified != seats {
+			t.Fatalf("verifier computed %d seats, prover %d", verified, seats)
+		}
+		total += seats
+	}
+	// expected n * weight * CommitteeSize / TotalWeight = 100 seats with a standard deviation of about 10
+	if total < 50 || total > 150 {
+		t.Errorf("selected %d seats, expected about 100", total)
+	}
+}
+
+func TestSortitionReject(t *testing.T) {
+	params := sortition.Params{CommitteeSize: 10, TotalWeight: 100}
+	sec := bls.NewSecretKey()
+	alpha := []byte("alpha")
+	proof, _, err := sortition.Select(&sec, alpha, 5, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := bls.NewSecretKey()
+	if _, err := sortition.Verify(other.GetPublicKey(), alpha, &proof, 5, params); err != sortition.ErrInvalidProof {
+		t.Errorf("expected ErrInvalidProof, got %v", err)
+	}
+	if _, err := sortition.Verify(sec.GetPublicKey(), []byte("beta"), &proof, 5, params); err != sortition.ErrInvalidProof {
+		t.Errorf("expected ErrInvalidProof, got %v", err)
+	}
+}
+
+func TestSortitionSerializationMode(t *testing.T) {
+	params := sortition.Params{CommitteeSize: 100, TotalWeight: 200}
+	sec := bls.NewSecretKey()
+	alpha := []byte("alpha")
+	proof, seats, err := sortition.Select(&sec, alpha, 100, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bls.SetETHserialization(true)
+	defer bls.SetETHserialization(false)
+	if verified, err := sortition.Verify(sec.GetPublicKey(), alpha, &proof, 100, params); err != nil || verified != seats {
+		t.Errorf("verified %d seats in ETH mode, selected %d: %v", verified, seats, err)
+	}
+}
+
+func TestSortitionSeats(t *testing.T) {
+	params := sortition.Params{CommitteeSize: 50, TotalWeight: 1000}
+	var low, high, mid [bls.VRFOutputSize]byte
+	for i := range high {
+		high[i] = 0xff
+	}
+	mid[0] = 0x80
+
+	seats, err := params.Seats(low, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seats != 0 {
+		t.Errorf("zero output won %d seats", seats)
+	}
+	seatsMid, err := params.Seats(mid, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the median of B(100, 0.05) is 5
+	if seatsMid != 5 {
+		t.Errorf("median output won %d seats, expected 5", seatsMid)
+	}
+	seatsHigh, err := params.Seats(high, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seatsHigh <= seatsMid || seatsHigh > 100 {
+		t.Errorf("max output won %d seats", seatsHigh)
+	}
+
+	// seats grow with the output
+	prev := uint64(0)
+	var out [bls.VRFOutputSize]byte
+	for i := uint64(0); i < 256; i++ {
+		binary.BigEndian.PutUint64(out[:8], i<<56)
+		s, err := params.Seats(out, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s < prev {
+			t.Fatalf("seats decrease from %d to %d", prev, s)
+		}
+		prev = s
+	}
+
+	if s, _ := params.Seats(mid, 0); s != 0 {
+		t.Errorf("zero weight won %d seats", s)
+	}
+	full := sortition.Params{CommitteeSize: 1000, TotalWeight: 1000}
+	if s, _ := full.Seats(low, 7); s != 7 {
+		t.Errorf("full committee gave %d seats, expected 7", s)
+	}
+}
+
+func TestSortitionBadParams(t *testing.T) {
+	var out [bls.VRFOutputSize]byte
+	for _, params := range []sortition.Params{
+		{CommitteeSize: 10, TotalWeight: 0},
+		{CommitteeSize: 0, TotalWeight: 10},
+		{CommitteeSize: 11, TotalWeight: 10},
+	} {
+		if _, err := params.Seats(out, 1); err == nil {
+			t.Errorf("expected error for %+v", params)
+		}
+	}
+	params := sortition.Params{CommitteeSize: 1, TotalWeight: 10}
+	if _, err := params.Seats(out, 11); err == nil {
+		t.Error("expected error for weight above total weight")
+	}
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/beacon"
+	"github.com/spacemeshos/go-bls/ibe"
+)
+
+func TestTimelock(t *testing.T) {
+	chain, committee := newBeaconCommittee(t, beacon.Unchained, 4, 3)
+	plaintext := []byte("sealed bid: 1000 SMH")
+	const round = 1000
+
+	ciphertext, err := beacon.TimelockEncrypt(&chain.GroupKey, round, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(ciphertext), "-----BEGIN BLS TIMELOCK-----") {
+		t.Errorf("ciphertext is not armored:\n%s", ciphertext)
+	}
+	r, err := beacon.TimelockRound(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r != round {
+		t.Errorf("bad round %d", r)
+	}
+
+	// an earlier round does not decrypt
+	early, err := chain.Combine(round-1, nil, committee.partials(t, chain, []int{0, 1, 2}, round-1, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var earlySig bls.Sign
+	if err := earlySig.Deserialize(early.Signature); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := beacon.TimelockDecrypt(&earlySig, ciphertext); err != ibe.ErrDecrypt {
+		t.Errorf("decrypted with the signature of round %d: %v", round-1, err)
+	}
+
+	// once the round is published it decrypts
+	b, err := chain.Combine(round, nil, committee.partials(t, chain, []int{3, 1, 0}, round, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sig bls.Sign
+	if err := sig.Deserialize(b.Signature); err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := beacon.TimelockDecrypt(&sig, ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("bad plaintext %q", decrypted)
+	}
+}
+
+func TestTimelockBadArmor(t *testing.T) {
+	var sig bls.Sign
+	if _, err := beacon.TimelockDecrypt(&sig, []byte("not armored")); err == nil {
+		t.Error("expected error for unarmored ciphertext")
+	}
+	sec := bls.NewSecretKey()
+	ciphertext, err := beacon.TimelockEncrypt(sec.GetPublicKey(), 5, []byte("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := strings.Replace(string(ciphertext), "Round: 5", "Round: five", 1)
+	if _, err := beacon.TimelockRound([]byte(bad)); err == nil {
+		t.Error("expected error for a bad round header")
+	}
+	bad = strings.Replace(string(ciphertext), "Version: 1", "Version: 9", 1)
+	if _, err := beacon.TimelockRound([]byte(bad)); err == nil {
+		t.Error("expected error for an unknown version")
+	}
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/tpke"
+)
+
+func TestTPKECombine(t *testing.T) {
+	const k, n = 3, 5
+	sec, idVec, secVec, pubVec, _ := makeShares(t, k, n, []byte("m"))
+	plaintext := []byte("threshold plaintext")
+	aad := []byte("context")
+	ct, err := tpke.Encrypt(sec.GetPublicKey(), plaintext, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares := make([]tpke.DecryptionShare, n)
+	for i := 0; i < n; i++ {
+		ds, err := tpke.DecryptShare(&secVec[i], &idVec[i], ct, aad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tpke.VerifyShare(&pubVec[i], ct, aad, ds) {
+			t.Errorf("share %d does not verify", i)
+		}
+		if tpke.VerifyShare(&pubVec[(i+1)%n], ct, aad, ds) {
+			t.Errorf("share %d verifies against another public key", i)
+		}
+		var ds2 tpke.DecryptionShare
+		if err := ds2.Deserialize(ds.Serialize()); err != nil {
+			t.Fatal(err)
+		}
+		if !tpke.VerifyShare(&pubVec[i], ct, aad, &ds2) {
+			t.Errorf("deserialized share %d does not verify", i)
+		}
+		shares[i] = *ds
+	}
+	for _, subset := range [][]tpke.DecryptionShare{shares[:k], shares[n-k:], {shares[0], shares[2], shares[4]}} {
+		out, err := tpke.Combine(ct, aad, subset, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out, plaintext) {
+			t.Errorf("bad plaintext %q", out)
+		}
+	}
+	if _, err := tpke.Combine(ct, aad, shares[:k-1], k); err == nil {
+		t.Error("expected error for too few shares")
+	}
+	if _, err := tpke.Combine(ct, aad, []tpke.DecryptionShare{shares[0], shares[0], shares[1]}, k); err == nil {
+		t.Error("expected error for duplicate shares")
+	}
+	bad := append([]tpke.DecryptionShare{}, shares[:k]...)
+	bad[1].D = bad[0].D
+	if _, err := tpke.Combine(ct, aad, bad, k); err != tpke.ErrDecrypt {
+		t.Errorf("expected ErrDecrypt for a bad share, got %v", err)
+	}
+}
+
+func TestTPKEBadCiphertext(t *testing.T) {
+	sec, idVec, secVec, _, _ := makeShares(t, 2, 2, []byte("m"))
+	aad := []byte("context")
+	ct, err := tpke.Encrypt(sec.GetPublicKey(), []byte("plaintext"), aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tpke.DecryptShare(&secVec[0], &idVec[0], ct, []byte("other")); err != tpke.ErrBadCiphertext {
+		t.Errorf("expected ErrBadCiphertext for wrong aad, got %v", err)
+	}
+	for _, i := range []int{0, 10, len(ct) - 1} {
+		mauled := append([]byte{}, ct...)
+		mauled[i] ^= 1
+		if _, err := tpke.DecryptShare(&secVec[0], &idVec[0], mauled, aad); err == nil {
+			t.Errorf("expected error for ciphertext modified at %d", i)
+		}
+	}
+	if _, err := tpke.DecryptShare(&secVec[0], &idVec[0], ct[:40], aad); err == nil {
+		t.Error("expected error for short ciphertext")
+	}
+	var other bls.SecretKey
+	other.SetByCSPRNG()
+	ds, err := tpke.DecryptShare(&other, &idVec[0], ct, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tpke.VerifyShare(secVec[0].GetPublicKey(), ct, aad, ds) {
+		t.Error("share of another key verifies")
+	}
+}
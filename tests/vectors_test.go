@@ -0,0 +1,487 @@
+package tests
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/eth"
+)
+
+// Conformance with the Ethereum BLS vectors and the IETF hash to curve and signature vectors, loaded
+// from testdata/vectors (see testdata/vectors/README.md). A pinned subset is checked in, the full
+// release of ethereum/bls12-381-tests can be extracted over it.
+//
+// Ethereum public keys are in G1 and signatures in G2, hashed with the IETF hash to curve: the
+// vectors run against the eth package, whose groups are swapped with respect to PublicKey and Sign.
+
+const vectorsDir = "testdata/vectors"
+
+// hashToG2DST -- domain separation tag of the hash_to_G2 vectors, those of RFC 9380
+const hashToG2DST = "QUUX-V01-CS02-with-BLS12381G2_XMD:SHA-256_SSWU_RO_"
+
+// ethVector -- a consensus-spec BLS test case in JSON
+type ethVector struct {
+	Input  json.RawMessage `json:"input"`
+	Output json.RawMessage `json:"output"`
+}
+
+var ethHandlers = map[string]func(t *testing.T, v *ethVector){
+	"sign":                      testETHSign,
+	"verify":                    testETHVerify,
+	"aggregate":                 testETHAggregate,
+	"fast_aggregate_verify":     testETHFastAggregateVerify,
+	"eth_fast_aggregate_verify": testETHEthFastAggregateVerify,
+	"aggregate_verify":          testETHAggregateVerify,
+	"eth_aggregate_pubkeys":     testETHAggregatePubkeys,
+	"deserialization_G1":        testETHDeserializationG1,
+	"deserialization_G2":        testETHDeserializationG2,
+	"hash_to_G2":                testETHHashToG2,
+}
+
+func TestETHVectors(t *testing.T) {
+	root := filepath.Join(vectorsDir, "eth")
+	ran := map[string]int{}
+	for _, file := range vectorFiles(t, root) {
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handler := ethHandler(rel)
+		run, ok := ethHandlers[handler]
+		if !ok {
+			continue
+		}
+		ran[handler]++
+		t.Run(filepath.ToSlash(rel), func(t *testing.T) {
+			buf, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var v ethVector
+			if err := json.Unmarshal(buf, &v); err != nil {
+				t.Fatal(err)
+			}
+			run(t, &v)
+		})
+	}
+	for _, name := range ethHandlerNames() {
+		if ran[name] == 0 {
+			t.Errorf("no %s vectors in %s", name, root)
+		}
+	}
+}
+
+func ethHandlerNames() []string {
+	names := make([]string, 0, len(ethHandlers))
+	for name := range ethHandlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TestETHSerialization checks the encodings of the G1 generator and of infinity without vector files
+func TestETHSerialization(t *testing.T) {
+	bls.SetETHserialization(true)
+	defer bls.SetETHserialization(false)
+	const g1 = "97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb"
+	var p, neg bls.G1
+	if !deserializeG1(g1, &p) {
+		t.Fatal("G1 generator does not deserialize")
+	}
+	if hex.EncodeToString(p.Serialize()) != g1 {
+		t.Errorf("bad G1 generator encoding %x", p.Serialize())
+	}
+	bls.G1Neg(&neg, &p)
+	if hex.EncodeToString(neg.Serialize()) != "b7"+g1[2:] {
+		t.Errorf("bad encoding of the negated G1 generator %x", neg.Serialize())
+	}
+	var zero bls.G1
+	if hex.EncodeToString(zero.Serialize()) != "c0"+strings.Repeat("00", 47) {
+		t.Errorf("bad encoding of infinity %x", zero.Serialize())
+	}
+	if deserializeG1("80"+strings.Repeat("00", 47), &p) {
+		t.Error("infinity without the infinity flag deserializes")
+	}
+}
+
+// TestIETFHashToG2 runs the BLS12381G2_XMD:SHA-256_SSWU_RO_ vectors of RFC 9380
+func TestIETFHashToG2(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join(vectorsDir, "ietf", "BLS12381G2_XMD-SHA-256_SSWU_RO_.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suite struct {
+		DST     string `json:"dst"`
+		Vectors []struct {
+			Msg string `json:"msg"`
+			P   struct {
+				X string `json:"x"`
+				Y string `json:"y"`
+			} `json:"P"`
+		} `json:"vectors"`
+	}
+	if err := json.Unmarshal(buf, &suite); err != nil {
+		t.Fatal(err)
+	}
+	if len(suite.Vectors) == 0 {
+		t.Fatal("no hash to curve vectors")
+	}
+	for _, v := range suite.Vectors {
+		p, err := eth.HashToG2([]byte(v.Msg), []byte(suite.DST))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !isG2Affine(&p, v.P.X, v.P.Y) {
+			t.Errorf("bad hash of %q: %s", v.Msg, p.GetString(16))
+		}
+	}
+}
+
+// TestIETFBasicSignVectors runs the signatures in G2 of the basic scheme of the reference
+// implementation of the IETF BLS signature draft: lines of message, ikm and signature in hex
+func TestIETFBasicSignVectors(t *testing.T) {
+	f, err := os.Open(filepath.Join(vectorsDir, "ietf", "sig_g2_basic_P256.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	const dst = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_NUL_"
+	bls.SetETHserialization(true)
+	defer bls.SetETHserialization(false)
+	n := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			t.Fatalf("bad line %d", n+1)
+		}
+		msg, err1 := hex.DecodeString(fields[0])
+		ikm, err2 := hex.DecodeString(fields[1])
+		if err1 != nil || err2 != nil {
+			t.Fatalf("bad line %d", n+1)
+		}
+		h, err := eth.HashToG2(msg, []byte(dst))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sig bls.G2
+		sec := ietfKeyGen(t, ikm)
+		bls.G2Mul(&sig, &h, &sec)
+		if hex.EncodeToString(sig.Serialize()) != fields[2] {
+			t.Errorf("bad signature on line %d\n%x\n%s", n+1, sig.Serialize(), fields[2])
+		}
+		n++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n == 0 {
+		t.Fatal("no signature vectors")
+	}
+}
+
+// ietfKeyGen -- KeyGen of the reference implementation, which salts HKDF with "BLS-SIG-KEYGEN-SALT-"
+// itself before hashing it, where bls.KeyGen (draft 4) hashes it first
+func ietfKeyGen(t *testing.T, ikm []byte) (sec bls.Fr) {
+	r, _ := new(big.Int).SetString(bls.GetCurveOrder(), 10)
+	salt := []byte("BLS-SIG-KEYGEN-SALT-")
+	okm := make([]byte, 48)
+	x := new(big.Int)
+	for x.Sign() == 0 {
+		if _, err := io.ReadFull(hkdf.New(sha256.New, append(append([]byte{}, ikm...), 0), salt, []byte{0, 48}), okm); err != nil {
+			t.Fatal(err)
+		}
+		x.SetBytes(okm).Mod(x, r)
+		h := sha256.Sum256(salt)
+		salt = h[:]
+	}
+	if err := sec.SetString(x.String(), 10); err != nil {
+		t.Fatal(err)
+	}
+	return sec
+}
+
+func testETHSign(t *testing.T, v *ethVector) {
+	var in struct {
+		Privkey string `json:"privkey"`
+		Message string `json:"message"`
+	}
+	var out *string
+	decodeVector(t, v, &in, &out)
+	var sec eth.SecretKey
+	if err := sec.Deserialize(vectorBytes(t, in.Privkey)); err != nil {
+		if out != nil {
+			t.Errorf("valid secret key rejected: %v", err)
+		}
+		return
+	}
+	sig := sec.Sign(vectorBytes(t, in.Message))
+	switch {
+	case out == nil:
+		t.Error("expected an invalid secret key")
+	case hex.EncodeToString(sig.Serialize()) != strings.TrimPrefix(*out, "0x"):
+		t.Errorf("bad signature\n%x\n%s", sig.Serialize(), *out)
+	case !eth.Verify(sec.PublicKey(), vectorBytes(t, in.Message), sig):
+		t.Error("signature does not verify")
+	}
+}
+
+func testETHVerify(t *testing.T, v *ethVector) {
+	var in struct {
+		Pubkey    string `json:"pubkey"`
+		Message   string `json:"message"`
+		Signature string `json:"signature"`
+	}
+	var valid bool
+	decodeVector(t, v, &in, &valid)
+	var pub eth.PublicKey
+	var sig eth.Signature
+	ok := pub.Deserialize(vectorBytes(t, in.Pubkey)) == nil &&
+		sig.Deserialize(vectorBytes(t, in.Signature)) == nil &&
+		eth.Verify(&pub, vectorBytes(t, in.Message), &sig)
+	if ok != valid {
+		t.Errorf("verified %v, expected %v", ok, valid)
+	}
+}
+
+// testETHAggregate -- Aggregate of signatures, the output is null for invalid inputs
+func testETHAggregate(t *testing.T, v *ethVector) {
+	var in []string
+	var out *string
+	decodeVector(t, v, &in, &out)
+	sigs, ok := ethSignatures(t, in)
+	var sum []byte
+	if ok {
+		agg, err := eth.Aggregate(sigs)
+		if ok = err == nil; ok {
+			sum = agg.Serialize()
+		}
+	}
+	checkAggregate(t, ok, sum, out)
+}
+
+func testETHFastAggregateVerify(t *testing.T, v *ethVector) {
+	testETHFastAggregate(t, v, eth.FastAggregateVerify)
+}
+
+func testETHEthFastAggregateVerify(t *testing.T, v *ethVector) {
+	testETHFastAggregate(t, v, eth.EthFastAggregateVerify)
+}
+
+func testETHFastAggregate(t *testing.T, v *ethVector, verify func([]eth.PublicKey, []byte, *eth.Signature) bool) {
+	var in struct {
+		Pubkeys   []string `json:"pubkeys"`
+		Message   string   `json:"message"`
+		Signature string   `json:"signature"`
+	}
+	var valid bool
+	decodeVector(t, v, &in, &valid)
+	pubs, ok := ethPublicKeys(t, in.Pubkeys)
+	var sig eth.Signature
+	ok = ok && sig.Deserialize(vectorBytes(t, in.Signature)) == nil &&
+		verify(pubs, vectorBytes(t, in.Message), &sig)
+	if ok != valid {
+		t.Errorf("verified %v, expected %v", ok, valid)
+	}
+}
+
+func testETHAggregateVerify(t *testing.T, v *ethVector) {
+	var in struct {
+		Pubkeys   []string `json:"pubkeys"`
+		Messages  []string `json:"messages"`
+		Signature string   `json:"signature"`
+	}
+	var valid bool
+	decodeVector(t, v, &in, &valid)
+	pubs, ok := ethPublicKeys(t, in.Pubkeys)
+	msgs := make([][]byte, len(in.Messages))
+	for i := range msgs {
+		msgs[i] = vectorBytes(t, in.Messages[i])
+	}
+	var sig eth.Signature
+	ok = ok && sig.Deserialize(vectorBytes(t, in.Signature)) == nil && eth.AggregateVerify(pubs, msgs, &sig)
+	if ok != valid {
+		t.Errorf("verified %v, expected %v", ok, valid)
+	}
+}
+
+// testETHAggregatePubkeys -- eth_aggregate_pubkeys, the output is null for invalid inputs
+func testETHAggregatePubkeys(t *testing.T, v *ethVector) {
+	var in []string
+	var out *string
+	decodeVector(t, v, &in, &out)
+	pubs, ok := ethPublicKeys(t, in)
+	var sum []byte
+	if ok {
+		agg, err := eth.AggregatePublicKeys(pubs)
+		if ok = err == nil; ok {
+			sum = agg.Serialize()
+		}
+	}
+	checkAggregate(t, ok, sum, out)
+}
+
+func testETHDeserializationG1(t *testing.T, v *ethVector) {
+	var in struct {
+		Pubkey string `json:"pubkey"`
+	}
+	var valid bool
+	decodeVector(t, v, &in, &valid)
+	var pub eth.PublicKey
+	if ok := pub.Deserialize(vectorBytes(t, in.Pubkey)) == nil; ok != valid {
+		t.Errorf("deserialized %v, expected %v", ok, valid)
+	}
+}
+
+func testETHDeserializationG2(t *testing.T, v *ethVector) {
+	var in struct {
+		Signature string `json:"signature"`
+	}
+	var valid bool
+	decodeVector(t, v, &in, &valid)
+	var sig eth.Signature
+	if ok := sig.Deserialize(vectorBytes(t, in.Signature)) == nil; ok != valid {
+		t.Errorf("deserialized %v, expected %v", ok, valid)
+	}
+}
+
+// testETHHashToG2 -- the output holds the affine coordinates "0x<c0>,0x<c1>"
+func testETHHashToG2(t *testing.T, v *ethVector) {
+	var in struct {
+		Msg string `json:"msg"`
+	}
+	var out struct {
+		X string `json:"x"`
+		Y string `json:"y"`
+	}
+	decodeVector(t, v, &in, &out)
+	p, err := eth.HashToG2([]byte(in.Msg), []byte(hashToG2DST))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isG2Affine(&p, out.X, out.Y) {
+		t.Errorf("bad hash of %q: %s", in.Msg, p.GetString(16))
+	}
+}
+
+// isG2Affine reports whether p is (x, y), with the coordinates given as "0x<c0>,0x<c1>"
+func isG2Affine(p *bls.G2, x, y string) bool {
+	want := strings.Split(x+","+y, ",")
+	got := strings.Fields(p.GetString(16))
+	if len(got) != 5 || got[0] != "1" || len(want) != 4 {
+		return false
+	}
+	for i := range want {
+		a, ok1 := new(big.Int).SetString(strings.TrimPrefix(want[i], "0x"), 16)
+		b, ok2 := new(big.Int).SetString(got[i+1], 16)
+		if !ok1 || !ok2 || a.Cmp(b) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func ethPublicKeys(t *testing.T, in []string) ([]eth.PublicKey, bool) {
+	pubs := make([]eth.PublicKey, len(in))
+	for i := range in {
+		if pubs[i].Deserialize(vectorBytes(t, in[i])) != nil {
+			return nil, false
+		}
+	}
+	return pubs, true
+}
+
+func ethSignatures(t *testing.T, in []string) ([]eth.Signature, bool) {
+	sigs := make([]eth.Signature, len(in))
+	for i := range in {
+		if sigs[i].Deserialize(vectorBytes(t, in[i])) != nil {
+			return nil, false
+		}
+	}
+	return sigs, true
+}
+
+func checkAggregate(t *testing.T, ok bool, sum []byte, out *string) {
+	switch {
+	case out == nil && ok:
+		t.Error("expected invalid inputs")
+	case out != nil && !ok:
+		t.Error("valid inputs rejected")
+	case out != nil && hex.EncodeToString(sum) != strings.TrimPrefix(*out, "0x"):
+		t.Errorf("bad aggregate\n%x\n%s", sum, *out)
+	}
+}
+
+func decodeVector(t *testing.T, v *ethVector, in interface{}, out interface{}) {
+	if err := json.Unmarshal(v.Input, in); err != nil {
+		t.Fatalf("bad input: %v", err)
+	}
+	if err := json.Unmarshal(v.Output, out); err != nil {
+		t.Fatalf("bad output: %v", err)
+	}
+}
+
+// vectorBytes decodes the hex of a vector, with or without 0x
+func vectorBytes(t *testing.T, s string) []byte {
+	buf, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		t.Fatalf("bad hex %q: %v", s, err)
+	}
+	return buf
+}
+
+// deserializeG1 reports whether s is the hex of a point of the subgroup of order r
+func deserializeG1(s string, p *bls.G1) bool {
+	buf, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return false
+	}
+	return p.Deserialize(buf) == nil && p.IsValidOrder()
+}
+
+// ethHandler returns the handler of a vector file from its path, either
+// <handler>/<case>.json (ethereum/bls12-381-tests) or .../bls/<handler>/bls/<case>/data.json (consensus-spec-tests)
+func ethHandler(rel string) string {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i := len(parts) - 2; i >= 0; i-- {
+		if _, ok := ethHandlers[parts[i]]; ok {
+			return parts[i]
+		}
+	}
+	return parts[0]
+}
+
+// vectorFiles returns the JSON files under root, failing the test if there are none
+func vectorFiles(t *testing.T, root string) []string {
+	var files []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(path, ".json") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("no vectors in %s, see %s/README.md", root, vectorsDir)
+	}
+	return files
+}
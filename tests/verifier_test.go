@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/verifier"
+)
+
+// verifierJobs returns valid and invalid jobs of every kind along with their expected results
+func verifierJobs(t testing.TB, n int) ([]verifier.Job, []bool) {
+	secVec := make([]bls.SecretKey, 4)
+	pubVec := make([]bls.PublicKey, 4)
+	for i := range secVec {
+		secVec[i].SetByCSPRNG()
+		pubVec[i] = *secVec[i].GetPublicKey()
+	}
+	var jobs []verifier.Job
+	var expected []bool
+	for i := 0; i < n; i++ {
+		msg := []byte(fmt.Sprintf("message %d", i))
+		valid := i%3 != 0
+		signed := msg
+		if !valid {
+			signed = []byte("other")
+		}
+		switch i % 4 {
+		case 0, 1:
+			jobs = append(jobs, verifier.NewSingleJob(&pubVec[0], msg, secVec[0].Sign(signed)))
+		case 2:
+			messages := [][]byte{msg, []byte("second")}
+			sig := secVec[0].Sign(signed)
+			sig.Add(secVec[1].Sign(messages[1]))
+			jobs = append(jobs, verifier.NewAggregateJob(pubVec[:2], messages, sig))
+		case 3:
+			sig := secVec[0].Sign(signed)
+			for j := 1; j < len(secVec); j++ {
+				sig.Add(secVec[j].Sign(msg))
+			}
+			jobs = append(jobs, verifier.NewFastAggregateJob(pubVec, msg, sig))
+		}
+		expected = append(expected, valid)
+	}
+	return jobs, expected
+}
+
+func TestVerifier(t *testing.T) {
+	jobs, expected := verifierJobs(t, 40)
+	for _, config := range []verifier.Config{{}, {Workers: 1, BatchSize: 1}, {Workers: 2, BatchSize: 64}} {
+		v := verifier.New(context.Background(), config)
+		results := make([]<-chan verifier.Result, len(jobs))
+		for i := range jobs {
+			results[i] = v.Submit(context.Background(), jobs[i])
+		}
+		for i := range results {
+			r := <-results[i]
+			if r.Err != nil {
+				t.Fatalf("%+v: job %d: %v", config, i, r.Err)
+			}
+			if r.Valid != expected[i] {
+				t.Errorf("%+v: job %d (%v): valid %v, expected %v", config, i, jobs[i].Kind, r.Valid, expected[i])
+			}
+		}
+		v.Close()
+	}
+}
+
+func TestVerifierAllValidBatch(t *testing.T) {
+	jobs, expected := verifierJobs(t, 30)
+	v := verifier.New(context.Background(), verifier.Config{Workers: 1, BatchSize: 32})
+	defer v.Close()
+	var wg sync.WaitGroup
+	for i := range jobs {
+		if !expected[i] {
+			continue
+		}
+		i := i
+		wg.Add(1)
+		err := v.SubmitFunc(context.Background(), jobs[i], func(r verifier.Result) {
+			defer wg.Done()
+			if r.Err != nil || !r.Valid {
+				t.Errorf("job %d: %v %v", i, r.Valid, r.Err)
+			}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+}
+
+func TestVerifierBadJobs(t *testing.T) {
+	v := verifier.New(context.Background(), verifier.Config{})
+	defer v.Close()
+	var sec bls.SecretKey
+	sec.SetByCSPRNG()
+	pub := sec.GetPublicKey()
+	sig := sec.Sign([]byte("m"))
+	bad := []verifier.Job{
+		{Kind: verifier.Single, Signature: *sig},
+		verifier.NewAggregateJob([]bls.PublicKey{*pub, *pub}, [][]byte{[]byte("m"), []byte("m")}, sig),
+		verifier.NewFastAggregateJob(nil, []byte("m"), sig),
+		verifier.NewSingleJob(pub, nil, sig),
+		{Kind: verifier.Kind(7), PublicKeys: []bls.PublicKey{*pub}, Messages: [][]byte{[]byte("m")}, Signature: *sig},
+	}
+	for i, job := range bad {
+		if valid, err := v.Verify(context.Background(), job); valid || err == nil {
+			t.Errorf("bad job %d: %v %v", i, valid, err)
+		}
+	}
+	var zero bls.PublicKey
+	var zeroSig bls.Sign
+	if valid, err := v.Verify(context.Background(), verifier.NewSingleJob(&zero, []byte("m"), &zeroSig)); valid || err != nil {
+		t.Errorf("zero public key: %v %v", valid, err)
+	}
+}
+
+func TestVerifierCancel(t *testing.T) {
+	jobs, _ := verifierJobs(t, 4)
+	v := verifier.New(context.Background(), verifier.Config{Workers: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := v.Verify(ctx, jobs[1]); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	v.Close()
+	if r := <-v.Submit(context.Background(), jobs[1]); r.Err != verifier.ErrClosed {
+		t.Errorf("expected ErrClosed, got %v", r.Err)
+	}
+
+	parent, stop := context.WithCancel(context.Background())
+	v = verifier.New(parent, verifier.Config{Workers: 1})
+	stop()
+	if r := <-v.Submit(context.Background(), jobs[1]); r.Err == nil {
+		t.Error("expected an error after the verifier context is done")
+	}
+	v.Close()
+}
+
+func TestVerifierCancelNoHang(t *testing.T) {
+	jobs, _ := verifierJobs(t, 4)
+	parent, stop := context.WithCancel(context.Background())
+	v := verifier.New(parent, verifier.Config{Workers: 2, QueueSize: 64})
+	defer v.Close()
+	stop()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// jobs submitted after the workers stopped must still complete
+		for i := 0; i < 1000; i++ {
+			if _, err := v.Verify(context.Background(), jobs[i%len(jobs)]); err == nil {
+				t.Error("expected an error after the verifier context is done")
+				return
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Verify hangs after the verifier context is done")
+	}
+}
+
+func benchmarkVerifier(b *testing.B, batchSize int) {
+	jobs, expected := verifierJobs(b, 64)
+	valid := jobs[:0]
+	for i := range jobs {
+		if expected[i] && jobs[i].Kind == verifier.Single {
+			valid = append(valid, jobs[i])
+		}
+	}
+	v := verifier.New(context.Background(), verifier.Config{BatchSize: batchSize})
+	defer v.Close()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		results := make([]<-chan verifier.Result, len(valid))
+		for i := range valid {
+			results[i] = v.Submit(context.Background(), valid[i])
+		}
+		for i := range results {
+			if r := <-results[i]; !r.Valid {
+				b.Fatal(r.Err)
+			}
+		}
+	}
+}
+
+func BenchmarkVerifierNoBatch(b *testing.B) { benchmarkVerifier(b, 1) }
+func BenchmarkVerifierBatch16(b *testing.B) { benchmarkVerifier(b, 16) }
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+func TestVRF(t *testing.T) {
+	sec := bls.NewSecretKey()
+	pub := sec.GetPublicKey()
+	alpha := []byte("layer 42")
+
+	proof, output := bls.VRFProve(&sec, alpha)
+	out, ok := bls.VRFVerify(pub, alpha, &proof)
+	if !ok {
+		t.Fatal("valid proof does not verify")
+	}
+	if out != output {
+		t.Error("verifier output differs from prover output")
+	}
+	if bls.VRFOutput(&proof) != output {
+		t.Error("VRFOutput differs from prover output")
+	}
+
+	// deterministic: the same input always gives the same output
+	proof2, output2 := bls.VRFProve(&sec, alpha)
+	if !proof.IsEqual(&proof2) || output != output2 {
+		t.Error("VRF is not deterministic")
+	}
+
+	// different inputs give different outputs
+	_, output3 := bls.VRFProve(&sec, []byte("layer 43"))
+	if output == output3 {
+		t.Error("different inputs give the same output")
+	}
+}
+
+func TestVRFReject(t *testing.T) {
+	sec := bls.NewSecretKey()
+	pub := sec.GetPublicKey()
+	alpha := []byte("layer 42")
+	proof, _ := bls.VRFProve(&sec, alpha)
+
+	if _, ok := bls.VRFVerify(pub, []byte("layer 43"), &proof); ok {
+		t.Error("proof verifies for another input")
+	}
+	other := bls.NewSecretKey()
+	if _, ok := bls.VRFVerify(other.GetPublicKey(), alpha, &proof); ok {
+		t.Error("proof verifies for another key")
+	}
+	// an ordinary signature on alpha is not a VRF proof
+	if _, ok := bls.VRFVerify(pub, alpha, sec.Sign(alpha)); ok {
+		t.Error("plain signature verifies as a proof")
+	}
+	var zeroPub bls.PublicKey
+	var zeroProof bls.Sign
+	if _, ok := bls.VRFVerify(&zeroPub, alpha, &zeroProof); ok {
+		t.Error("zero key and proof verify")
+	}
+}
+
+func TestVRFSerializationMode(t *testing.T) {
+	sec := bls.NewSecretKey()
+	alpha := []byte("layer 42")
+	proof, output := bls.VRFProve(&sec, alpha)
+	bls.SetETHserialization(true)
+	defer bls.SetETHserialization(false)
+	if bls.VRFOutput(&proof) != output {
+		t.Error("VRF output depends on the serialization mode")
+	}
+	if out, ok := bls.VRFVerify(sec.GetPublicKey(), alpha, &proof); !ok || out != output {
+		t.Error("verifier output depends on the serialization mode")
+	}
+}
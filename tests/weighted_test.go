@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/spacemeshos/go-bls"
+	"github.com/spacemeshos/go-bls/weighted"
+)
+
+func TestWeightedThreshold(t *testing.T) {
+	c := weighted.Committee{Weights: []uint64{5, 1, 3, 0, 2, 4}, Threshold: 8}
+	if c.TotalWeight() != 15 {
+		t.Fatalf("bad total weight %d", c.TotalWeight())
+	}
+	var sec bls.SecretKey
+	sec.SetByCSPRNG()
+	shares, mpk, err := c.Deal(&sec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mpk[0].IsEqual(sec.GetPublicKey()) {
+		t.Fatal("master public key does not start with the group key")
+	}
+	msg := []byte("weighted message")
+	partials := make([]weighted.Partial, len(shares))
+	for i := range shares {
+		partials[i] = *shares[i].Sign(msg)
+		if !c.VerifyPartial(mpk, &partials[i], msg) {
+			t.Errorf("partial of member %d does not verify", i)
+		}
+	}
+	expected := sec.Sign(msg)
+	for _, members := range [][]int{{0, 2}, {0, 1, 4}, {2, 4, 5}, {5, 0}, {1, 2, 4, 5}, {0, 1, 2, 3, 4, 5}} {
+		subset := make([]weighted.Partial, len(members))
+		for k, m := range members {
+			subset[k] = partials[m]
+		}
+		sig, err := c.Combine(subset)
+		if err != nil {
+			t.Fatalf("members %v: %v", members, err)
+		}
+		if !sig.IsEqual(expected) || !sig.Verify(sec.GetPublicKey(), msg) {
+			t.Errorf("members %v: bad signature", members)
+		}
+	}
+	for _, members := range [][]int{{0, 1}, {2, 4, 1}, {5, 2}, {0, 0, 1}} {
+		subset := make([]weighted.Partial, len(members))
+		for k, m := range members {
+			subset[k] = partials[m]
+		}
+		if c.Weight(subset) >= c.Threshold {
+			t.Fatalf("members %v: bad weight %d", members, c.Weight(subset))
+		}
+		if _, err := c.Combine(subset); err == nil {
+			t.Errorf("members %v: expected error below threshold", members)
+		}
+	}
+
+	// a partial with a bad signature or of another member
+	bad := partials[0]
+	bad.Signatures = append([]bls.Sign{}, bad.Signatures...)
+	bad.Signatures[2] = *shares[0].Keys[2].Sign([]byte("other"))
+	if c.VerifyPartial(mpk, &bad, msg) {
+		t.Error("bad partial verifies")
+	}
+	moved := partials[2]
+	moved.Member = 4
+	if c.VerifyPartial(mpk, &moved, msg) {
+		t.Error("partial verifies for another member")
+	}
+	if _, err := c.Combine([]weighted.Partial{moved, partials[0]}); err == nil {
+		t.Error("expected error for a partial with the wrong number of signatures")
+	}
+}
+
+func TestWeightedCommitteeValidate(t *testing.T) {
+	for _, c := range []weighted.Committee{
+		{Weights: []uint64{1, 2}, Threshold: 0},
+		{Weights: []uint64{1, 2}, Threshold: 4},
+		{Weights: nil, Threshold: 1},
+		{Weights: []uint64{weighted.MaxTotalWeight, 1}, Threshold: 1},
+	} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("expected error for %+v", c)
+		}
+	}
+	c := weighted.Committee{Weights: []uint64{3}, Threshold: 3}
+	if err := c.Validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,290 @@
+// Package tpke implements threshold public key encryption to a group PublicKey whose secret
+// is shared among committee members with SecretKey.Set, so that any Threshold members can
+// jointly decrypt without reconstructing the group secret.
+//
+// With Q the generator of public keys and groupPub = sQ, a sender encrypts with
+//
+//	U = rQ, K = r groupPub, key = HKDF-SHA256(K, info = "BLS-TPKE-V1" | U)
+//	ciphertext = 0x01 | U | c | z | AES-256-GCM(key, plaintext, aad = 0x01 | U | aad)
+//
+// where (c, z) is a Schnorr proof of knowledge of r bound to the encrypted body and aad,
+// so members only produce decryption shares for well-formed ciphertexts. Member i with share
+// s_i publishes D_i = s_i U with a Chaum-Pedersen proof that log_Q(pub_i) = log_U(D_i), and
+// any Threshold valid shares are combined with Lagrange interpolation in G2 into K = sU.
+package tpke
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spacemeshos/go-bls"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Version -- the version byte of ciphertexts
+const Version byte = 1
+
+// domain separation tags
+const (
+	kemInfo   = "BLS-TPKE-V1"
+	popTag    = "BLS-TPKE-POK-V1"
+	dleqTag   = "BLS-TPKE-DLEQ-V1"
+	keySize   = 32
+	nonceSize = 12
+)
+
+// ErrDecrypt is returned when the combined shares do not decrypt the ciphertext
+var ErrDecrypt = errors.New("err tpke:decryption failed")
+
+// ErrBadCiphertext is returned for malformed ciphertexts or ciphertexts with an invalid proof
+var ErrBadCiphertext = errors.New("err tpke:bad ciphertext")
+
+// Proof -- a Fiat-Shamir proof (challenge, response)
+type Proof struct {
+	C bls.Fr
+	Z bls.Fr
+}
+
+// DecryptionShare -- the decryption share of the member with the given id
+type DecryptionShare struct {
+	ID    bls.ID
+	D     bls.G2
+	Proof Proof
+}
+
+// Encrypt encrypts plaintext to the group public key, authenticating aad which must be given to DecryptShare and Combine
+func Encrypt(groupPub *bls.PublicKey, plaintext []byte, aad []byte) ([]byte, error) {
+	var r, w bls.Fr
+	for r.IsZero() {
+		r.SetByCSPRNG()
+	}
+	for w.IsZero() {
+		w.SetByCSPRNG()
+	}
+	q := bls.GetGeneratorOfG2()
+	var u, k, wq bls.G2
+	bls.G2Mul(&u, &q, &r)
+	bls.G2Mul(&k, bls.CastFromPublicKey(groupPub), &r)
+	bls.G2Mul(&wq, &q, &w)
+
+	header := append([]byte{Version}, u.Serialize()...)
+	aead, err := newAEAD(&k, &u)
+	if err != nil {
+		return nil, err
+	}
+	body := aead.Seal(nil, make([]byte, nonceSize), plaintext, append(append([]byte{}, header...), aad...))
+
+	// c = H(U, wQ, body, aad), z = w + c r
+	var c, z bls.Fr
+	if err := hashToFr(&c, popTag, u.Serialize(), wq.Serialize(), body, aad); err != nil {
+		return nil, err
+	}
+	bls.FrMul(&z, &c, &r)
+	bls.FrAdd(&z, &z, &w)
+
+	out := append(header, c.Serialize()...)
+	out = append(out, z.Serialize()...)
+	return append(out, body...), nil
+}
+
+// DecryptShare returns the decryption share of the member holding share with the given id
+// It fails on ciphertexts that are malformed or whose proof does not verify
+func DecryptShare(share *bls.SecretKey, id *bls.ID, ciphertext []byte, aad []byte) (*DecryptionShare, error) {
+	u, _, err := parse(ciphertext, aad)
+	if err != nil {
+		return nil, err
+	}
+	ds := &DecryptionShare{ID: *id}
+	s := bls.CastFromSecretKey(share)
+	bls.G2Mul(&ds.D, u, s)
+
+	// Chaum-Pedersen: A1 = wQ, A2 = wU, c = H(pub, U, D, A1, A2), z = w + c s
+	var w bls.Fr
+	for w.IsZero() {
+		w.SetByCSPRNG()
+	}
+	q := bls.GetGeneratorOfG2()
+	var a1, a2 bls.G2
+	bls.G2Mul(&a1, &q, &w)
+	bls.G2Mul(&a2, u, &w)
+	pub := share.GetPublicKey()
+	if err := hashToFr(&ds.Proof.C, dleqTag, pub.Serialize(), u.Serialize(), ds.D.Serialize(), a1.Serialize(), a2.Serialize()); err != nil {
+		return nil, err
+	}
+	bls.FrMul(&ds.Proof.Z, &ds.Proof.C, s)
+	bls.FrAdd(&ds.Proof.Z, &ds.Proof.Z, &w)
+	return ds, nil
+}
+
+// VerifyShare checks a decryption share against the public key share of its member (see PublicKey.Set)
+func VerifyShare(pubShare *bls.PublicKey, ciphertext []byte, aad []byte, ds *DecryptionShare) bool {
+	u, _, err := parse(ciphertext, aad)
+	if err != nil {
+		return false
+	}
+	if ds.D.IsZero() || !ds.D.IsValidOrder() {
+		return false
+	}
+	// A1 = zQ - c pub, A2 = zU - c D
+	q := bls.GetGeneratorOfG2()
+	var a1, a2, t bls.G2
+	bls.G2Mul(&a1, &q, &ds.Proof.Z)
+	bls.G2Mul(&t, bls.CastFromPublicKey(pubShare), &ds.Proof.C)
+	bls.G2Sub(&a1, &a1, &t)
+	bls.G2Mul(&a2, u, &ds.Proof.Z)
+	bls.G2Mul(&t, &ds.D, &ds.Proof.C)
+	bls.G2Sub(&a2, &a2, &t)
+	var c bls.Fr
+	if err := hashToFr(&c, dleqTag, pubShare.Serialize(), u.Serialize(), ds.D.Serialize(), a1.Serialize(), a2.Serialize()); err != nil {
+		return false
+	}
+	return c.IsEqual(&ds.Proof.C)
+}
+
+// Combine decrypts the ciphertext from the first threshold shares with distinct ids
+// Shares should be checked with VerifyShare first: an invalid share makes Combine fail
+func Combine(ciphertext []byte, aad []byte, shares []DecryptionShare, threshold int) ([]byte, error) {
+	u, body, err := parse(ciphertext, aad)
+	if err != nil {
+		return nil, err
+	}
+	if threshold <= 0 {
+		return nil, fmt.Errorf("err tpke:bad threshold %d", threshold)
+	}
+	idVec := make([]bls.ID, 0, threshold)
+	dVec := make([]bls.PublicKey, 0, threshold)
+	for i := range shares {
+		if len(idVec) == threshold {
+			break
+		}
+		duplicate := false
+		for j := range idVec {
+			if idVec[j].IsEqual(&shares[i].ID) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			idVec = append(idVec, shares[i].ID)
+			dVec = append(dVec, *bls.CastToPublicKey(&shares[i].D))
+		}
+	}
+	if len(idVec) < threshold {
+		return nil, fmt.Errorf("err tpke:%d distinct shares, need %d", len(idVec), threshold)
+	}
+	var k bls.PublicKey
+	if err := k.Recover(dVec, idVec); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(bls.CastFromPublicKey(&k), u)
+	if err != nil {
+		return nil, err
+	}
+	header := ciphertext[:1+len(u.Serialize())]
+	plaintext, err := aead.Open(nil, make([]byte, nonceSize), body, append(append([]byte{}, header...), aad...))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// Serialize returns id | D | c | z
+func (ds *DecryptionShare) Serialize() []byte {
+	out := append([]byte{}, ds.ID.GetLittleEndian()...)
+	out = append(out, ds.D.Serialize()...)
+	out = append(out, ds.Proof.C.Serialize()...)
+	return append(out, ds.Proof.Z.Serialize()...)
+}
+
+// Deserialize reads a share written by Serialize
+func (ds *DecryptionShare) Deserialize(buf []byte) error {
+	frSize, g2Size := sizes()
+	if len(buf) != 3*frSize+g2Size {
+		return fmt.Errorf("err tpke:bad share size %d", len(buf))
+	}
+	if err := ds.ID.SetLittleEndian(buf[:frSize]); err != nil {
+		return err
+	}
+	buf = buf[frSize:]
+	if err := ds.D.Deserialize(buf[:g2Size]); err != nil {
+		return err
+	}
+	buf = buf[g2Size:]
+	if err := ds.Proof.C.Deserialize(buf[:frSize]); err != nil {
+		return err
+	}
+	return ds.Proof.Z.Deserialize(buf[frSize:])
+}
+
+// parse splits a ciphertext and checks the proof of knowledge of r, returning U and the encrypted body
+func parse(ciphertext []byte, aad []byte) (*bls.G2, []byte, error) {
+	frSize, g2Size := sizes()
+	if len(ciphertext) < 1+g2Size+2*frSize || ciphertext[0] != Version {
+		return nil, nil, ErrBadCiphertext
+	}
+	u := new(bls.G2)
+	if err := u.Deserialize(ciphertext[1 : 1+g2Size]); err != nil || u.IsZero() || !u.IsValidOrder() {
+		return nil, nil, ErrBadCiphertext
+	}
+	var c, z bls.Fr
+	rest := ciphertext[1+g2Size:]
+	if c.Deserialize(rest[:frSize]) != nil || z.Deserialize(rest[frSize:2*frSize]) != nil {
+		return nil, nil, ErrBadCiphertext
+	}
+	body := rest[2*frSize:]
+
+	// wQ = zQ - cU
+	q := bls.GetGeneratorOfG2()
+	var wq, t bls.G2
+	bls.G2Mul(&wq, &q, &z)
+	bls.G2Mul(&t, u, &c)
+	bls.G2Sub(&wq, &wq, &t)
+	var c2 bls.Fr
+	if err := hashToFr(&c2, popTag, u.Serialize(), wq.Serialize(), body, aad); err != nil || !c2.IsEqual(&c) {
+		return nil, nil, ErrBadCiphertext
+	}
+	return u, body, nil
+}
+
+// newAEAD returns AES-256-GCM keyed by HKDF-SHA256 over K; each key is used once so nonces are zero
+func newAEAD(k *bls.G2, u *bls.G2) (cipher.AEAD, error) {
+	key := make([]byte, keySize)
+	info := append([]byte(kemInfo), u.Serialize()...)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, k.Serialize(), nil, info), key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// hashToFr sets out to the hash of tag and the length-prefixed parts
+func hashToFr(out *bls.Fr, tag string, parts ...[]byte) error {
+	h := sha256.New()
+	h.Write([]byte(tag))
+	for _, p := range parts {
+		var n [8]byte
+		for i := 0; i < 8; i++ {
+			n[i] = byte(uint64(len(p)) >> (8 * uint(7-i)))
+		}
+		h.Write(n[:])
+		h.Write(p)
+	}
+	if !out.SetHashOf(h.Sum(nil)) {
+		return fmt.Errorf("err tpke:hash to Fr")
+	}
+	return nil
+}
+
+// sizes returns the serialized sizes of Fr and G2
+func sizes() (int, int) {
+	var x bls.Fr
+	q := bls.GetGeneratorOfG2()
+	return len(x.Serialize()), len(q.Serialize())
+}
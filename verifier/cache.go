@@ -0,0 +1,158 @@
+package verifier
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"hash"
+	"sync"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+// Cache -- a bounded LRU set of successfully verified jobs, safe for concurrent use
+// A job is keyed on its kind, public keys, message hashes and signature, so only an
+// identical job hits the cache. Failed verifications are never cached.
+// Points are keyed on their affine coordinates, so keys do not depend on SetETHserialization.
+type Cache struct {
+	lock      sync.Mutex
+	capacity  int
+	entries   map[cacheKey]*list.Element
+	order     *list.List // front is the most recently used
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// cacheKey -- SHA-256 of a job
+type cacheKey [sha256.Size]byte
+
+// CacheStats -- counters of a Cache
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Len       int
+	Capacity  int
+}
+
+// HitRate -- hits / (hits + misses), 0 before any lookup
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// NewCache returns a cache of at most capacity jobs
+func NewCache(capacity int) *Cache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &Cache{
+		capacity: capacity,
+		entries:  make(map[cacheKey]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// Contains reports whether job was verified successfully, counting a hit or a miss
+func (c *Cache) Contains(job *Job) bool {
+	return c.contains(jobKey(job))
+}
+
+// Add records job as verified successfully
+func (c *Cache) Add(job *Job) {
+	c.add(jobKey(job))
+}
+
+// Verify -- Sign.Verify short-circuited by the cache
+// It is false, without a lookup, for an empty msg or a zero or invalid pub or sig, as for a Verifier
+func (c *Cache) Verify(sig *bls.Sign, pub *bls.PublicKey, msg []byte) bool {
+	job := NewSingleJob(pub, msg, sig)
+	if valid, err := check(&job); err != nil || !valid {
+		return false
+	}
+	key := jobKey(&job)
+	if c.contains(key) {
+		return true
+	}
+	if !sig.Verify(pub, msg) {
+		return false
+	}
+	c.add(key)
+	return true
+}
+
+// Stats returns the counters of the cache
+func (c *Cache) Stats() CacheStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return CacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Len:       c.order.Len(),
+		Capacity:  c.capacity,
+	}
+}
+
+// Purge empties the cache, keeping its counters
+func (c *Cache) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries = make(map[cacheKey]*list.Element, c.capacity)
+	c.order.Init()
+}
+
+func (c *Cache) contains(key cacheKey) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return false
+	}
+	c.hits++
+	c.order.MoveToFront(e)
+	return true
+}
+
+func (c *Cache) add(key cacheKey) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+	if c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		delete(c.entries, oldest.Value.(cacheKey))
+		c.order.Remove(oldest)
+		c.evictions++
+	}
+	c.entries[key] = c.order.PushFront(key)
+}
+
+// jobKey -- SHA-256(kind | n | pub_1 .. pub_n | m | SHA-256(msg_1) .. SHA-256(msg_m) | sig)
+// where each point is written as its GetString(16) and a zero byte
+func jobKey(job *Job) (key cacheKey) {
+	h := sha256.New()
+	h.Write([]byte{byte(job.Kind), byte(len(job.PublicKeys) >> 24), byte(len(job.PublicKeys) >> 16), byte(len(job.PublicKeys) >> 8), byte(len(job.PublicKeys))})
+	for i := range job.PublicKeys {
+		writePoint(h, bls.CastFromPublicKey(&job.PublicKeys[i]).GetString(16))
+	}
+	h.Write([]byte{byte(len(job.Messages) >> 24), byte(len(job.Messages) >> 16), byte(len(job.Messages) >> 8), byte(len(job.Messages))})
+	for _, msg := range job.Messages {
+		m := sha256.Sum256(msg)
+		h.Write(m[:])
+	}
+	writePoint(h, bls.CastFromSign(&job.Signature).GetString(16))
+	h.Sum(key[:0])
+	return key
+}
+
+// writePoint -- a point string of jobKey
+func writePoint(h hash.Hash, s string) {
+	h.Write([]byte(s))
+	h.Write([]byte{0})
+}
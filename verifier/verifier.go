@@ -0,0 +1,434 @@
+// Package verifier verifies signatures on a bounded pool of workers.
+//
+// Jobs are queued with Submit or SubmitFunc and picked up by the workers, which drain
+// whatever else is queued, up to BatchSize jobs, and check them together with a single
+// final exponentiation:
+//
+//	e(-sum_i r_i sig_i, Q) * prod_i prod_j e(r_i H(m_ij), pub_ij) = 1
+//
+// for random 64-bit r_i read with bls.ReadRand. If the batch fails the jobs are verified one by one, so each job
+// gets its own result whatever the others are.
+//
+// Gossiped messages are verified many times: a Cache set in Config short-circuits jobs
+// already verified successfully.
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+// Kind -- the kind of verification of a Job
+type Kind int
+
+const (
+	// Single -- one public key signed one message
+	Single Kind = iota
+	// Aggregate -- PublicKeys[i] signed Messages[i], messages must be distinct
+	Aggregate
+	// FastAggregate -- all PublicKeys signed Messages[0]
+	// only secure if the proofs of possession of all public keys were verified
+	FastAggregate
+)
+
+// String --
+func (k Kind) String() string {
+	switch k {
+	case Single:
+		return "single"
+	case Aggregate:
+		return "aggregate"
+	case FastAggregate:
+		return "fast-aggregate"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Job -- a signature to verify
+type Job struct {
+	Kind       Kind
+	PublicKeys []bls.PublicKey
+	Messages   [][]byte
+	Signature  bls.Sign
+}
+
+// NewSingleJob -- sig of msg under pub
+func NewSingleJob(pub *bls.PublicKey, msg []byte, sig *bls.Sign) Job {
+	return Job{Kind: Single, PublicKeys: []bls.PublicKey{*pub}, Messages: [][]byte{msg}, Signature: *sig}
+}
+
+// NewAggregateJob -- aggregated sig of messages[i] under pubVec[i]
+func NewAggregateJob(pubVec []bls.PublicKey, messages [][]byte, sig *bls.Sign) Job {
+	return Job{Kind: Aggregate, PublicKeys: pubVec, Messages: messages, Signature: *sig}
+}
+
+// NewFastAggregateJob -- aggregated sig of msg under all of pubVec
+func NewFastAggregateJob(pubVec []bls.PublicKey, msg []byte, sig *bls.Sign) Job {
+	return Job{Kind: FastAggregate, PublicKeys: pubVec, Messages: [][]byte{msg}, Signature: *sig}
+}
+
+// Result -- the result of a Job
+// Err is set if the job is malformed or was cancelled, in which case Valid is false
+type Result struct {
+	Valid bool
+	Err   error
+}
+
+// ErrClosed is returned for jobs submitted after Close
+var ErrClosed = errors.New("err verifier:closed")
+
+// Config -- the parameters of a Verifier, zero values select the defaults
+type Config struct {
+	// Workers -- number of verification goroutines, runtime.NumCPU() by default
+	Workers int
+	// BatchSize -- maximum number of jobs verified together, 16 by default, 1 disables batching
+	BatchSize int
+	// QueueSize -- number of jobs queued before Submit blocks, Workers * BatchSize by default
+	QueueSize int
+	// Cache -- if not nil, jobs found in it are valid without pairings and valid jobs are added to it
+	Cache *Cache
+}
+
+// request -- a queued job
+type request struct {
+	ctx  context.Context
+	job  Job
+	done func(Result)
+}
+
+// Verifier -- a pool of workers verifying jobs
+type Verifier struct {
+	config Config
+	ctx    context.Context
+	cancel context.CancelFunc
+	queue  chan request
+	lock   sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// New starts the workers of a Verifier, which stop when ctx is done or Close is called
+func New(ctx context.Context, config Config) *Verifier {
+	if config.Workers <= 0 {
+		config.Workers = runtime.NumCPU()
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 16
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = config.Workers * config.BatchSize
+	}
+	v := &Verifier{
+		config: config,
+		queue:  make(chan request, config.QueueSize),
+	}
+	v.ctx, v.cancel = context.WithCancel(ctx)
+	v.wg.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go v.worker()
+	}
+	return v
+}
+
+// Close stops accepting jobs, waits for the queued jobs to be verified and stops the workers
+func (v *Verifier) Close() {
+	v.lock.Lock()
+	if !v.closed {
+		v.closed = true
+		close(v.queue)
+	}
+	v.lock.Unlock()
+	v.wg.Wait()
+	v.cancel()
+}
+
+// SubmitFunc queues job, blocking while the queue is full, and calls done with its result from a worker
+// It returns an error, and does not call done, if the job could not be queued because ctx is done or v is closed
+// A job whose ctx is done before it is verified gets ctx.Err() as its result
+func (v *Verifier) SubmitFunc(ctx context.Context, job Job, done func(Result)) error {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+	if v.closed {
+		return ErrClosed
+	}
+	if err := v.ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case v.queue <- request{ctx: ctx, job: job, done: done}:
+		// the workers may have drained the queue and stopped since the check above
+		if v.ctx.Err() != nil {
+			v.drain()
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-v.ctx.Done():
+		return v.ctx.Err()
+	}
+}
+
+// Submit queues job and returns the channel its result is sent on
+func (v *Verifier) Submit(ctx context.Context, job Job) <-chan Result {
+	ch := make(chan Result, 1)
+	if err := v.SubmitFunc(ctx, job, func(r Result) { ch <- r }); err != nil {
+		ch <- Result{Err: err}
+	}
+	return ch
+}
+
+// Verify queues job and waits for its result
+func (v *Verifier) Verify(ctx context.Context, job Job) (bool, error) {
+	select {
+	case r := <-v.Submit(ctx, job):
+		return r.Valid, r.Err
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// worker verifies queued jobs in batches until the queue is closed or the verifier context is done
+func (v *Verifier) worker() {
+	defer v.wg.Done()
+	batch := make([]request, 0, v.config.BatchSize)
+	for {
+		var req request
+		var ok bool
+		select {
+		case req, ok = <-v.queue:
+		case <-v.ctx.Done():
+			v.drain()
+			return
+		}
+		if !ok {
+			return
+		}
+		batch = append(batch[:0], req)
+	fill:
+		for len(batch) < v.config.BatchSize {
+			select {
+			case req, ok = <-v.queue:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, req)
+			default:
+				break fill
+			}
+		}
+		v.verifyBatch(batch)
+	}
+}
+
+// drain fails the jobs left in the queue once the verifier context is done
+func (v *Verifier) drain() {
+	for {
+		select {
+		case req, ok := <-v.queue:
+			if !ok {
+				return
+			}
+			req.done(Result{Err: v.ctx.Err()})
+		default:
+			return
+		}
+	}
+}
+
+// prepared -- a job reduced to e(sig, Q) = prod_j e(hashes[j], pubs[j])
+type prepared struct {
+	req    *request
+	key    cacheKey
+	sig    *bls.G1
+	hashes []bls.G1
+	pubs   []bls.G2
+}
+
+func (v *Verifier) verifyBatch(batch []request) {
+	jobs := make([]prepared, 0, len(batch))
+	for i := range batch {
+		req := &batch[i]
+		if err := req.ctx.Err(); err != nil {
+			req.done(Result{Err: err})
+			continue
+		}
+		if err := v.ctx.Err(); err != nil {
+			req.done(Result{Err: err})
+			continue
+		}
+		var key cacheKey
+		if v.config.Cache != nil {
+			key = jobKey(&req.job)
+			if v.config.Cache.contains(key) {
+				req.done(Result{Valid: true})
+				continue
+			}
+		}
+		p, valid, err := prepare(req)
+		if err != nil || !valid {
+			req.done(Result{Err: err})
+			continue
+		}
+		p.key = key
+		jobs = append(jobs, p)
+	}
+	if len(jobs) > 1 && batchCheck(jobs) {
+		for i := range jobs {
+			v.finish(&jobs[i], true)
+		}
+		return
+	}
+	for i := range jobs {
+		v.finish(&jobs[i], finalCheck(jobs[i:i+1], nil))
+	}
+}
+
+// finish caches a valid job and reports its result
+func (v *Verifier) finish(p *prepared, valid bool) {
+	if valid && v.config.Cache != nil {
+		v.config.Cache.add(p.key)
+	}
+	p.req.done(Result{Valid: valid})
+}
+
+// check validates the shape of a job
+// valid is false, with a nil error, for jobs that can be rejected without pairings
+func check(job *Job) (valid bool, err error) {
+	n := len(job.PublicKeys)
+	switch job.Kind {
+	case Single:
+		if n != 1 || len(job.Messages) != 1 {
+			return false, fmt.Errorf("err verifier:single job with %d public keys and %d messages", n, len(job.Messages))
+		}
+	case Aggregate:
+		if n == 0 || len(job.Messages) != n {
+			return false, fmt.Errorf("err verifier:aggregate job with %d public keys and %d messages", n, len(job.Messages))
+		}
+		seen := make(map[string]bool, n)
+		for _, msg := range job.Messages {
+			if seen[string(msg)] {
+				return false, fmt.Errorf("err verifier:aggregate job with duplicate messages")
+			}
+			seen[string(msg)] = true
+		}
+	case FastAggregate:
+		if n == 0 || len(job.Messages) != 1 {
+			return false, fmt.Errorf("err verifier:fast aggregate job with %d public keys and %d messages", n, len(job.Messages))
+		}
+	default:
+		return false, fmt.Errorf("err verifier:unknown job kind %v", job.Kind)
+	}
+	for _, msg := range job.Messages {
+		if len(msg) == 0 {
+			return false, fmt.Errorf("err verifier:empty message")
+		}
+	}
+	if !job.Signature.IsValid() {
+		return false, nil
+	}
+	for i := range job.PublicKeys {
+		if !job.PublicKeys[i].IsValid() {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// prepare hashes the messages of a job and aggregates the public keys of fast aggregate jobs
+// valid is false, with a nil error, for jobs that can be rejected without pairings
+func prepare(req *request) (p prepared, valid bool, err error) {
+	job := &req.job
+	if valid, err := check(job); err != nil || !valid {
+		return p, false, err
+	}
+
+	p.req = req
+	p.sig = bls.CastFromSign(&job.Signature)
+	p.hashes = make([]bls.G1, len(job.Messages))
+	for i, msg := range job.Messages {
+		if err := p.hashes[i].HashAndMapTo(msg); err != nil {
+			return p, false, err
+		}
+	}
+	if job.Kind == FastAggregate {
+		var agg bls.G2
+		for i := range job.PublicKeys {
+			bls.G2Add(&agg, &agg, bls.CastFromPublicKey(&job.PublicKeys[i]))
+		}
+		if agg.IsZero() {
+			return p, false, nil
+		}
+		p.pubs = []bls.G2{agg}
+	} else {
+		p.pubs = make([]bls.G2, len(job.PublicKeys))
+		for i := range job.PublicKeys {
+			p.pubs[i] = *bls.CastFromPublicKey(&job.PublicKeys[i])
+		}
+	}
+	return p, true, nil
+}
+
+// millerLoops multiplies e into the Miller loops of p, with its sides scaled by r if r is not nil
+func millerLoops(e *bls.GT, sig *bls.G1, p *prepared, r *bls.Fr) {
+	var t bls.GT
+	var h bls.G1
+	for j := range p.hashes {
+		if r != nil {
+			bls.G1Mul(&h, &p.hashes[j], r)
+		} else {
+			h = p.hashes[j]
+		}
+		bls.MillerLoop(&t, &h, &p.pubs[j])
+		bls.GTMul(e, e, &t)
+	}
+	if r != nil {
+		var s bls.G1
+		bls.G1Mul(&s, p.sig, r)
+		bls.G1Add(sig, sig, &s)
+	} else {
+		bls.G1Add(sig, sig, p.sig)
+	}
+}
+
+// batchCheck verifies all jobs at once with random 64-bit coefficients
+func batchCheck(jobs []prepared) bool {
+	rVec := make([]bls.Fr, len(jobs))
+	buf := make([]byte, 8*len(jobs))
+	if err := bls.ReadRand(buf); err != nil {
+		return false
+	}
+	for i := range rVec {
+		b := buf[8*i : 8*i+8]
+		b[0] |= 1 // nonzero
+		if err := rVec[i].SetLittleEndian(b); err != nil {
+			return false
+		}
+	}
+	return finalCheck(jobs, rVec)
+}
+
+// finalCheck checks e(-sum_i r_i sig_i, Q) * prod_i prod_j e(r_i H_ij, pub_ij) = 1, with r_i = 1 if rVec is nil
+func finalCheck(jobs []prepared, rVec []bls.Fr) bool {
+	var e, t bls.GT
+	var sig, neg bls.G1
+	e.SetInt64(1)
+	for i := range jobs {
+		var r *bls.Fr
+		if rVec != nil {
+			r = &rVec[i]
+		}
+		millerLoops(&e, &sig, &jobs[i], r)
+	}
+	q := bls.GetGeneratorOfG2()
+	bls.G1Neg(&neg, &sig)
+	bls.MillerLoop(&t, &neg, &q)
+	bls.GTMul(&e, &e, &t)
+	bls.FinalExp(&e, &e)
+	return e.IsOne()
+}
@@ -0,0 +1,78 @@
+package bls
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"strings"
+)
+
+// ---------------- Verifiable Random Function --------------------
+// BLS signatures are unique, so a signature over the VRF input is a proof that
+// determines a single output which anyone can check with the public key:
+//
+//	proof  = sec.Sign(VRFInputTag | alpha)
+//	output = SHA256(VRFOutputTag | x | y)
+//
+// where x and y are the affine coordinates of the proof as 48-byte big-endian integers, so
+// that the output does not depend on SetETHserialization.
+// The input tag keeps VRF proofs apart from ordinary signatures made with the same key
+// and the output tag separates the output from other hashes of the signature.
+
+// VRFInputTag -- domain separation prefix of the signed VRF input
+const VRFInputTag = "BLS-VRF-INPUT-V1-"
+
+// VRFOutputTag -- domain separation prefix of the hashed VRF proof
+const VRFOutputTag = "BLS-VRF-OUTPUT-V1-"
+
+// VRFOutputSize -- size of a VRF output in bytes
+const VRFOutputSize = sha256.Size
+
+// VRFProve returns the VRF proof and output of sec on alpha
+func VRFProve(sec *SecretKey, alpha []byte) (proof Sign, output [VRFOutputSize]byte) {
+	proof = *sec.Sign(vrfInput(alpha))
+	return proof, VRFOutput(&proof)
+}
+
+// VRFVerify checks proof against pub and alpha and returns the VRF output if it is valid
+func VRFVerify(pub *PublicKey, alpha []byte, proof *Sign) (output [VRFOutputSize]byte, ok bool) {
+	if pub.v.IsZero() || proof.v.IsZero() {
+		return output, false
+	}
+	if !proof.Verify(pub, vrfInput(alpha)) {
+		return output, false
+	}
+	return VRFOutput(proof), true
+}
+
+// VRFOutput returns the output determined by proof
+// It does not verify the proof: use VRFVerify on proofs from other parties
+func VRFOutput(proof *Sign) (output [VRFOutputSize]byte) {
+	h := sha256.New()
+	h.Write([]byte(VRFOutputTag))
+	h.Write(affineG1(&proof.v))
+	copy(output[:], h.Sum(nil))
+	return output
+}
+
+// vrfFpSize -- size of a coordinate in the VRF output hash
+const vrfFpSize = 48
+
+// affineG1 returns x | y of P, all zeros for the point at infinity
+func affineG1(P *G1) []byte {
+	out := make([]byte, 2*vrfFpSize)
+	coords := strings.Fields(P.GetString(16)) // "1 x y" or "0"
+	if len(coords) != 3 {
+		return out
+	}
+	for i, c := range coords[1:] {
+		var n big.Int
+		n.SetString(c, 16)
+		n.FillBytes(out[i*vrfFpSize : (i+1)*vrfFpSize])
+	}
+	return out
+}
+
+// vrfInput returns the message signed for alpha
+func vrfInput(alpha []byte) []byte {
+	return append([]byte(VRFInputTag), alpha...)
+}
@@ -0,0 +1,195 @@
+// Package weighted implements threshold signatures for committees of members with unequal weights.
+//
+// A member of weight w holds w virtual shares of the group secret: with offset the total
+// weight of the members before it, member i holds the shares of ids offset+1 .. offset+w of a
+// polynomial of degree Threshold-1 (see SecretKey.Set). Any members whose weights add up to
+// Threshold sign with all their shares and the signatures of Threshold virtual shares are
+// combined with Sign.Recover into a standard signature under the group PublicKey.
+package weighted
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-bls"
+)
+
+// MaxTotalWeight -- the maximum total weight of a committee, the number of virtual shares
+const MaxTotalWeight = 1 << 16
+
+// Committee -- the weights of the members and the weight needed to sign
+type Committee struct {
+	Weights   []uint64
+	Threshold uint64
+}
+
+// Share -- the virtual shares of a member
+type Share struct {
+	Member int
+	Keys   []bls.SecretKey
+}
+
+// Partial -- the signatures of a message with the virtual shares of a member
+type Partial struct {
+	Member     int
+	Signatures []bls.Sign
+}
+
+// Validate --
+func (c *Committee) Validate() error {
+	total, err := c.totalWeight()
+	if err != nil {
+		return err
+	}
+	if c.Threshold == 0 || c.Threshold > total {
+		return fmt.Errorf("err weighted:threshold %d out of total weight %d", c.Threshold, total)
+	}
+	return nil
+}
+
+// TotalWeight -- the sum of the weights
+func (c *Committee) TotalWeight() uint64 {
+	total, _ := c.totalWeight()
+	return total
+}
+
+// Deal splits sec into the shares of the members and returns them with the master public key
+func (c *Committee) Deal(sec *bls.SecretKey) ([]Share, []bls.PublicKey, error) {
+	if err := c.Validate(); err != nil {
+		return nil, nil, err
+	}
+	msk := sec.GetMasterSecretKey(int(c.Threshold))
+	shares := make([]Share, len(c.Weights))
+	for i := range c.Weights {
+		idVec := c.ids(i)
+		shares[i] = Share{Member: i, Keys: make([]bls.SecretKey, len(idVec))}
+		for k := range idVec {
+			if err := shares[i].Keys[k].Set(msk, &idVec[k]); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return shares, bls.GetMasterPublicKey(msk), nil
+}
+
+// Sign signs msg with every virtual share
+func (s *Share) Sign(msg []byte) *Partial {
+	p := &Partial{Member: s.Member, Signatures: make([]bls.Sign, len(s.Keys))}
+	for k := range s.Keys {
+		p.Signatures[k] = *s.Keys[k].Sign(msg)
+	}
+	return p
+}
+
+// PublicShares returns the public keys of the virtual shares of member
+func (c *Committee) PublicShares(mpk []bls.PublicKey, member int) ([]bls.PublicKey, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if member < 0 || member >= len(c.Weights) {
+		return nil, fmt.Errorf("err weighted:unknown member %d", member)
+	}
+	if uint64(len(mpk)) != c.Threshold {
+		return nil, fmt.Errorf("err weighted:master public key of %d keys for threshold %d", len(mpk), c.Threshold)
+	}
+	idVec := c.ids(member)
+	pubVec := make([]bls.PublicKey, len(idVec))
+	for k := range idVec {
+		if err := pubVec[k].Set(mpk, &idVec[k]); err != nil {
+			return nil, err
+		}
+	}
+	return pubVec, nil
+}
+
+// VerifyPartial checks every signature of p against the public shares of its member
+func (c *Committee) VerifyPartial(mpk []bls.PublicKey, p *Partial, msg []byte) bool {
+	pubVec, err := c.PublicShares(mpk, p.Member)
+	if err != nil || len(pubVec) != len(p.Signatures) || len(msg) == 0 {
+		return false
+	}
+	for k := range pubVec {
+		if !p.Signatures[k].Verify(&pubVec[k], msg) {
+			return false
+		}
+	}
+	return true
+}
+
+// Weight -- the total weight of the distinct members of partials
+func (c *Committee) Weight(partials []Partial) uint64 {
+	seen := make(map[int]bool, len(partials))
+	var w uint64
+	for i := range partials {
+		m := partials[i].Member
+		if m >= 0 && m < len(c.Weights) && !seen[m] {
+			seen[m] = true
+			w += c.Weights[m]
+		}
+	}
+	return w
+}
+
+// Combine recovers the group signature from partials of distinct members whose weights add up to the threshold
+// Partials should be checked with VerifyPartial first: an invalid partial gives an invalid signature
+func (c *Committee) Combine(partials []Partial) (*bls.Sign, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	idVec := make([]bls.ID, 0, c.Threshold)
+	signVec := make([]bls.Sign, 0, c.Threshold)
+	seen := make(map[int]bool, len(partials))
+	for i := range partials {
+		p := &partials[i]
+		if p.Member < 0 || p.Member >= len(c.Weights) {
+			return nil, fmt.Errorf("err weighted:unknown member %d", p.Member)
+		}
+		if seen[p.Member] {
+			continue
+		}
+		seen[p.Member] = true
+		ids := c.ids(p.Member)
+		if len(p.Signatures) != len(ids) {
+			return nil, fmt.Errorf("err weighted:member %d has %d signatures for weight %d", p.Member, len(p.Signatures), len(ids))
+		}
+		for k := 0; k < len(ids) && uint64(len(idVec)) < c.Threshold; k++ {
+			idVec = append(idVec, ids[k])
+			signVec = append(signVec, p.Signatures[k])
+		}
+		if uint64(len(idVec)) == c.Threshold {
+			var sig bls.Sign
+			if err := sig.Recover(signVec, idVec); err != nil {
+				return nil, err
+			}
+			return &sig, nil
+		}
+	}
+	return nil, fmt.Errorf("err weighted:weight %d below threshold %d", len(idVec), c.Threshold)
+}
+
+// totalWeight -- the sum of the weights, which must not exceed MaxTotalWeight
+func (c *Committee) totalWeight() (uint64, error) {
+	var total uint64
+	for i, w := range c.Weights {
+		if w > MaxTotalWeight || total+w > MaxTotalWeight {
+			return 0, fmt.Errorf("err weighted:total weight above %d at member %d", MaxTotalWeight, i)
+		}
+		total += w
+	}
+	return total, nil
+}
+
+// ids returns the ids of the virtual shares of member, which is valid
+func (c *Committee) ids(member int) []bls.ID {
+	var offset uint64
+	for i := 0; i < member; i++ {
+		offset += c.Weights[i]
+	}
+	idVec := make([]bls.ID, c.Weights[member])
+	for k := range idVec {
+		v := offset + uint64(k) + 1
+		if err := idVec[k].SetLittleEndian([]byte{byte(v), byte(v >> 8), byte(v >> 16)}); err != nil {
+			panic(err)
+		}
+	}
+	return idVec
+}